@@ -0,0 +1,31 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const boolFormats = "want true or false, yes or no, on or off, y or n, enabled or disabled, or 1 or 0"
+
+// lenientBools maps the lower case words the lenient tag option accepts
+var lenientBools = map[string]bool{
+	"1": true, "t": true, "true": true, "y": true, "yes": true, "on": true, "enabled": true,
+	"0": false, "f": false, "false": false, "n": false, "no": false, "off": false, "disabled": false,
+}
+
+// parseBool parses a bool the way the tag and options ask, an empty value
+// is true with presence and the vocabulary is case-insensitive with lenient
+func parseBool(v string, t tag, opts Options) (bool, error) {
+	if v == "" && (t.Presence || opts.PresenceBools) {
+		return true, nil
+	}
+	if !t.Lenient && !opts.LenientBools {
+		return strconv.ParseBool(v)
+	}
+	b, ok := lenientBools[strings.ToLower(v)]
+	if !ok {
+		return false, fmt.Errorf("invalid boolean %q (%s)", v, boolFormats)
+	}
+	return b, nil
+}
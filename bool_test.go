@@ -0,0 +1,270 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+func TestLenientBools(t *testing.T) {
+	cases := []struct {
+		value string
+		err   error
+		want  bool
+	}{
+		{value: "true", err: nil, want: true},
+		{value: "1", err: nil, want: true},
+		{value: "T", err: nil, want: true},
+		{value: "yes", err: nil, want: true},
+		{value: "YES", err: nil, want: true},
+		{value: "y", err: nil, want: true},
+		{value: "On", err: nil, want: true},
+		{value: "enabled", err: nil, want: true},
+		{value: "false", err: nil, want: false},
+		{value: "0", err: nil, want: false},
+		{value: "no", err: nil, want: false},
+		{value: "N", err: nil, want: false},
+		{value: "off", err: nil, want: false},
+		{value: "Disabled", err: nil, want: false},
+		{
+			value: "",
+			err:   fmt.Errorf(`env: unable to parse ['DEBUG'=''] as bool for field 'Debug': invalid boolean "" (want true or false, yes or no, on or off, y or n, enabled or disabled, or 1 or 0)`),
+		},
+		{
+			value: "maybe",
+			err:   fmt.Errorf(`env: unable to parse ['DEBUG'='maybe'] as bool for field 'Debug': invalid boolean "maybe" (want true or false, yes or no, on or off, y or n, enabled or disabled, or 1 or 0)`),
+		},
+		{
+			value: " yes",
+			err:   fmt.Errorf(`env: unable to parse ['DEBUG'=' yes'] as bool for field 'Debug': invalid boolean " yes" (want true or false, yes or no, on or off, y or n, enabled or disabled, or 1 or 0)`),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			src := env.MapSource{"DEBUG": c.value}
+			obj := &struct {
+				Debug bool `env:"DEBUG,lenient"`
+			}{}
+			err := env.Unmarshal(obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if obj.Debug != c.want {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, obj.Debug)
+				}
+			}
+		})
+	}
+}
+
+func TestPresenceBools(t *testing.T) {
+	src := env.MapSource{
+		"DEBUG":   "",
+		"VERBOSE": "off",
+		"TRACE":   "false",
+	}
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want interface{}
+	}{
+		{
+			name: "set but empty is true",
+			obj: &struct {
+				Debug   bool  `env:"DEBUG,presence"`
+				Pointer *bool `env:"DEBUG,presence"`
+				Trace   bool  `env:"TRACE,presence"`
+				Unset   bool  `env:"UNSET,presence"`
+			}{},
+			err: nil,
+			want: &struct {
+				Debug   bool  `env:"DEBUG,presence"`
+				Pointer *bool `env:"DEBUG,presence"`
+				Trace   bool  `env:"TRACE,presence"`
+				Unset   bool  `env:"UNSET,presence"`
+			}{Debug: true, Pointer: ptrBool(true), Trace: false, Unset: false},
+		},
+		{
+			name: "with lenient",
+			obj: &struct {
+				Debug   bool `env:"DEBUG,presence,lenient"`
+				Verbose bool `env:"VERBOSE,presence,lenient"`
+			}{},
+			err: nil,
+			want: &struct {
+				Debug   bool `env:"DEBUG,presence,lenient"`
+				Verbose bool `env:"VERBOSE,presence,lenient"`
+			}{Debug: true, Verbose: false},
+		},
+		{
+			name: "without lenient",
+			obj: &struct {
+				Verbose bool `env:"VERBOSE,presence"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['VERBOSE'='off'] as bool for field 'Verbose': invalid syntax"),
+			want: nil,
+		},
+		{
+			name: "without the option",
+			obj: &struct {
+				Debug bool `env:"DEBUG"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['DEBUG'=''] as bool for field 'Debug': invalid syntax"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+}
+
+func TestBoolOptions(t *testing.T) {
+	src := env.MapSource{"DEBUG": "", "VERBOSE": "yes", "FLAGS": "on,Off,1"}
+
+	t.Run("off by default", func(t *testing.T) {
+		_, err := env.AsBool("VERBOSE", env.Options{Source: src})
+		want := `env: unable to parse ['VERBOSE'='yes'] as bool`
+		if err == nil || err.Error() != want || !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("AsBool lenient", func(t *testing.T) {
+		got, err := env.AsBool("VERBOSE", env.Options{Source: src, LenientBools: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", true, got)
+		}
+	})
+
+	t.Run("AsBool lenient invalid", func(t *testing.T) {
+		_, err := env.AsBool("V", env.Options{Source: env.MapSource{"V": "maybe"}, LenientBools: true})
+		// the accepted formats are shown once the option is on
+		want := `env: unable to parse ['V'='maybe'] as bool: invalid boolean "maybe" (want true or false, yes or no, on or off, y or n, enabled or disabled, or 1 or 0)`
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("AsBool presence", func(t *testing.T) {
+		got, err := env.AsBool("DEBUG", env.Options{Source: src, PresenceBools: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", true, got)
+		}
+	})
+
+	t.Run("AsBoolOr unset", func(t *testing.T) {
+		got, err := env.AsBoolOr("UNSET", false, env.Options{Source: src, PresenceBools: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", false, got)
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var cfg struct {
+			Debug   bool   `env:"DEBUG"`
+			Verbose bool   `env:"VERBOSE"`
+			Flags   []bool `env:"FLAGS"`
+		}
+		if err := env.Unmarshal(&cfg, env.Options{Source: src, LenientBools: true, PresenceBools: true}); err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.Debug || !cfg.Verbose || !reflect.DeepEqual([]bool{true, false, true}, cfg.Flags) {
+			t.Errorf("\ngot:'%#v'\n", cfg)
+		}
+	})
+
+	t.Run("NewSchema", func(t *testing.T) {
+		s, err := env.NewSchema(&struct {
+			Debug   bool            `env:"DEBUG,default=off"`
+			Verbose *bool           `env:"VERBOSE,lenient"`
+			Flags   map[string]bool `env:"FLAGS,presence"`
+			Level   int             `env:"LEVEL"`
+		}{}, env.Options{LenientBools: true, PresenceBools: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"DEBUG":   "DEBUG,lenient,presence,default=off",
+			"VERBOSE": "VERBOSE,lenient,presence",
+			"FLAGS":   "FLAGS,presence,lenient",
+			"LEVEL":   "LEVEL",
+		}
+		for name, tag := range want {
+			if got := s.Properties[name].Tag; got != tag {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", tag, got)
+			}
+		}
+	})
+}
+
+func TestBoolSchema(t *testing.T) {
+	type config struct {
+		Strict   bool `env:"STRICT"`
+		Lenient  bool `env:"LENIENT,lenient"`
+		Presence bool `env:"PRESENCE,presence"`
+		Both     bool `env:"BOTH,lenient,presence"`
+	}
+	s, err := env.NewSchema(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patterns and enums must agree with the parser
+	for _, name := range []string{"STRICT", "LENIENT", "PRESENCE", "BOTH"} {
+		p := s.Properties[name]
+		for _, v := range []string{"", "1", "0", "t", "TRUE", "False", "yes", "NO", "On", "off", "y", "N", "Enabled", "disabled", "maybe", "yess", " yes", "tRUE"} {
+			err := env.Unmarshal(&config{}, env.Options{Source: env.MapSource{name: v}})
+			matches := false
+			if p.Pattern != "" {
+				matches = regexp.MustCompile(p.Pattern).MatchString(v)
+			}
+			for _, e := range p.Enum {
+				matches = matches || e == v
+			}
+			if want := err == nil; want != matches {
+				t.Errorf("%s %q: parser accepts %v, schema matches %v", name, v, want, matches)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Command envcheck validates the current environment, or dotenv files,
+// against a schema exported by env.JSONSchema without compiling the service
+// the schema came from.
+//
+//	envcheck -schema config.schema.json [-prefix APP_] [file.env ...]
+//
+// It reports missing required variables, values the env parsers reject and,
+// with -prefix, variables under the prefix that the schema does not know.
+// The exit status is 1 when problems are found and 2 on usage errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("schema", "", "JSON schema exported by env.JSONSchema (required)")
+	prefix := fs.String("prefix", "", "report variables with this prefix that are not in the schema")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: envcheck -schema file [-prefix prefix] [file.env ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaFile == "" {
+		fs.Usage()
+		return 2
+	}
+
+	schema, err := readSchema(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "envcheck: %s\n", err)
+		return 2
+	}
+
+	// dotenv files replace the environment, earlier files take precedence
+	var src env.Source = env.OS
+	if fs.NArg() != 0 {
+		src, err = env.ReadDotenv(fs.Args()...)
+		if err != nil {
+			fmt.Fprintf(stderr, "envcheck: %s\n", err)
+			return 2
+		}
+	}
+
+	problems := check(schema, *prefix, env.Options{Source: src})
+	if len(problems) == 0 {
+		fmt.Fprintf(stdout, "envcheck: ok, %d variables checked\n", len(schema.Properties))
+		return 0
+	}
+	fmt.Fprintf(stdout, "envcheck: %d problems\n", len(problems))
+	for _, p := range problems {
+		fmt.Fprintf(stdout, "  %s\n", p)
+	}
+	return 1
+}
+
+func readSchema(name string) (*env.Schema, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var s env.Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &s, nil
+}
+
+// check unmarshals src into a struct built from the schema, so values go
+// through the same parsers, defaults and tag options as in the service
+func check(schema *env.Schema, prefix string, opts env.Options) []string {
+	src := opts.Source
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]reflect.StructField, len(names))
+	for i, name := range names {
+		p := schema.Properties[name]
+		rt, ok := parseType(p.GoType)
+		if !ok {
+			// custom types can only be checked for presence
+			rt = reflect.TypeOf("")
+		}
+		tag := p.Tag
+		if tag == "" {
+			tag = name
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: rt,
+			Tag:  reflect.StructTag("env:" + strconv.Quote(tag)),
+		}
+	}
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("F%d", len(fields)),
+				Type: reflect.TypeOf(""),
+				Tag:  reflect.StructTag("env:" + strconv.Quote(name+",required")),
+			})
+		}
+	}
+
+	cfg := reflect.New(reflect.StructOf(fields))
+	err := env.Unmarshal(cfg.Interface(), opts)
+
+	var problems []string
+	var errs env.Errors
+	errors.As(err, &errs)
+	for _, err := range errs {
+		problems = append(problems, describe(err, schema))
+	}
+
+	if prefix != "" {
+		if e, ok := src.(env.Enumerator); ok {
+			for _, k := range e.Keys() {
+				if _, known := schema.Properties[k]; strings.HasPrefix(k, prefix) && !known {
+					problems = append(problems, fmt.Sprintf("%s: unknown variable", k))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func describe(err error, schema *env.Schema) string {
+	var re *env.RequiredError
+	var pe *env.ParseError
+	switch {
+	case errors.As(err, &re):
+		return fmt.Sprintf("%s: required variable is not set", re.Var)
+	case errors.As(err, &pe):
+		value := strconv.Quote(pe.Value)
+		if pe.Sensitive {
+			value = pe.Value
+		}
+		if pe.Default {
+			value = "default " + value
+		}
+		reason := pe.Err.Error()
+		var ne *strconv.NumError
+		if errors.As(pe.Err, &ne) {
+			reason = ne.Err.Error()
+		}
+		return fmt.Sprintf("%s: invalid %s %s: %s", pe.Var, schema.Properties[pe.Var].GoType, value, reason)
+	}
+	return err.Error()
+}
+
+var basicTypes = map[string]reflect.Type{
+	"string":        reflect.TypeOf(""),
+	"bool":          reflect.TypeOf(false),
+	"int":           reflect.TypeOf(int(0)),
+	"int8":          reflect.TypeOf(int8(0)),
+	"int16":         reflect.TypeOf(int16(0)),
+	"int32":         reflect.TypeOf(int32(0)),
+	"int64":         reflect.TypeOf(int64(0)),
+	"uint":          reflect.TypeOf(uint(0)),
+	"uint8":         reflect.TypeOf(uint8(0)),
+	"uint16":        reflect.TypeOf(uint16(0)),
+	"uint32":        reflect.TypeOf(uint32(0)),
+	"uint64":        reflect.TypeOf(uint64(0)),
+	"float32":       reflect.TypeOf(float32(0)),
+	"float64":       reflect.TypeOf(float64(0)),
+	"time.Duration": reflect.TypeOf(time.Duration(0)),
+	"env.ByteSize":  reflect.TypeOf(env.ByteSize(0)),
+
+	// common encoding.TextUnmarshaler types
+	"time.Time":      reflect.TypeOf(time.Time{}),
+	"net.IP":         reflect.TypeOf(net.IP{}),
+	"netip.Addr":     reflect.TypeOf(netip.Addr{}),
+	"netip.AddrPort": reflect.TypeOf(netip.AddrPort{}),
+	"netip.Prefix":   reflect.TypeOf(netip.Prefix{}),
+	"big.Int":        reflect.TypeOf(big.Int{}),
+	"big.Float":      reflect.TypeOf(big.Float{}),
+}
+
+// parseType turns the x-go-type of a schema property back into a type the
+// env parsers understand, e.g. "map[string][]int"
+func parseType(s string) (reflect.Type, bool) {
+	if rt, ok := basicTypes[s]; ok {
+		return rt, true
+	}
+	switch {
+	case strings.HasPrefix(s, "*"):
+		if elem, ok := parseType(s[1:]); ok {
+			return reflect.PtrTo(elem), true
+		}
+	case strings.HasPrefix(s, "[]"):
+		if elem, ok := parseType(s[2:]); ok {
+			return reflect.SliceOf(elem), true
+		}
+	case strings.HasPrefix(s, "map[") && strings.Contains(s, "]"):
+		end := strings.Index(s, "]")
+		key, keyOK := parseType(s[4:end])
+		elem, elemOK := parseType(s[end+1:])
+		if keyOK && elemOK && key.Comparable() {
+			return reflect.MapOf(key, elem), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+type config struct {
+	Port     int               `env:"APP_PORT,default=8080"`
+	URL      string            `env:"APP_DB_URL,required"`
+	Password string            `env:"APP_PASSWORD,sensitive"`
+	Timeout  time.Duration     `env:"APP_TIMEOUT"`
+	Level    string            `env:"APP_LEVEL,enum=debug|info"`
+	Limits   map[string][]uint `env:"APP_LIMITS"`
+	Custom   CustomType        `env:"APP_CUSTOM,required"`
+	Debug    bool              `env:"APP_DEBUG"`
+}
+
+type CustomType struct{ v string }
+
+func (c *CustomType) UnmarshalENV(v string) error {
+	c.v = v
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	schema, err := env.JSONSchema((*config)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := filepath.Join(dir, "schema.json")
+	write(t, schemaFile, string(schema))
+
+	// required through Options.Required only, the tags do not say so
+	allRequired, err := env.JSONSchema(&struct {
+		URL  string `env:"DATABASE_URL"`
+		Port int    `env:"PORT"`
+		Host string `env:"HOST,default=localhost"`
+	}{}, env.Options{Required: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allRequiredFile := filepath.Join(dir, "required.json")
+	write(t, allRequiredFile, string(allRequired))
+	// global options are written into x-env-tag
+	withOptions, err := env.JSONSchema((*config)(nil), env.Options{ExtendedDurations: true, SINumbers: true, LenientBools: true, PresenceBools: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withOptionsFile := filepath.Join(dir, "options.json")
+	write(t, withOptionsFile, string(withOptions))
+	portOnly := filepath.Join(dir, "port.env")
+	write(t, portOnly, "PORT=1\n")
+
+	good := filepath.Join(dir, "good.env")
+	write(t, good, "APP_DB_URL=postgres://db\nAPP_CUSTOM=x\nAPP_LIMITS=a:1,b:2\n")
+	days := filepath.Join(dir, "days.env")
+	write(t, days, "APP_DB_URL=postgres://db\nAPP_CUSTOM=x\nAPP_TIMEOUT=P1DT12H\n")
+	si := filepath.Join(dir, "si.env")
+	write(t, si, "APP_DB_URL=postgres://db\nAPP_CUSTOM=x\nAPP_PORT=8_080\nAPP_LIMITS=a:1k\n")
+	yes := filepath.Join(dir, "yes.env")
+	write(t, yes, "APP_DB_URL=postgres://db\nAPP_CUSTOM=x\nAPP_DEBUG=Yes\n")
+	empty := filepath.Join(dir, "empty.env")
+	write(t, empty, "APP_DB_URL=postgres://db\nAPP_CUSTOM=x\nAPP_DEBUG=\n")
+	bad := filepath.Join(dir, "bad.env")
+	write(t, bad, "APP_PORT=http\nAPP_PASSWORD=1\nAPP_TIMEOUT=5 days\nAPP_LEVEL=trace\nAPP_LIMITS=a:-1\nAPP_DEBUGG=1\nOTHER=1\n")
+
+	cases := []struct {
+		name   string
+		args   []string
+		setEnv func(t *testing.T)
+		code   int
+		want   string
+	}{
+		{
+			name:   "missing schema flag",
+			args:   []string{},
+			setEnv: func(t *testing.T) {},
+			code:   2,
+			want:   "",
+		},
+		{
+			name:   "valid dotenv file",
+			args:   []string{"-schema", schemaFile, "-prefix", "APP_", good},
+			setEnv: func(t *testing.T) {},
+			code:   0,
+			want:   "envcheck: ok, 8 variables checked\n",
+		},
+		{
+			name:   "invalid dotenv files",
+			args:   []string{"-schema", schemaFile, "-prefix", "APP_", bad, good},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want: "envcheck: 5 problems\n" +
+				"  APP_LEVEL: invalid string \"trace\": must be one of debug, info\n" +
+				"  APP_LIMITS: invalid map[string][]uint \"a:-1\": invalid syntax\n" +
+				"  APP_PORT: invalid int \"http\": invalid syntax\n" +
+				"  APP_TIMEOUT: invalid time.Duration \"5 days\": time: unknown unit \" days\" in duration \"5 days\"\n" +
+				"  APP_DEBUGG: unknown variable\n",
+		},
+		{
+			name:   "extended durations",
+			args:   []string{"-schema", withOptionsFile, days},
+			setEnv: func(t *testing.T) {},
+			code:   0,
+			want:   "envcheck: ok, 8 variables checked\n",
+		},
+		{
+			name:   "strict durations",
+			args:   []string{"-schema", schemaFile, days},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want:   "envcheck: 1 problems\n  APP_TIMEOUT: invalid time.Duration \"P1DT12H\": time: invalid duration \"P1DT12H\"\n",
+		},
+		{
+			name:   "si numbers",
+			args:   []string{"-schema", withOptionsFile, si},
+			setEnv: func(t *testing.T) {},
+			code:   0,
+			want:   "envcheck: ok, 8 variables checked\n",
+		},
+		{
+			name:   "plain numbers",
+			args:   []string{"-schema", schemaFile, si},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want:   "envcheck: 1 problems\n  APP_LIMITS: invalid map[string][]uint \"a:1k\": invalid syntax\n",
+		},
+		{
+			name:   "lenient bools",
+			args:   []string{"-schema", withOptionsFile, yes},
+			setEnv: func(t *testing.T) {},
+			code:   0,
+			want:   "envcheck: ok, 8 variables checked\n",
+		},
+		{
+			name:   "strict bools",
+			args:   []string{"-schema", schemaFile, yes},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want:   "envcheck: 1 problems\n  APP_DEBUG: invalid bool \"Yes\": invalid syntax\n",
+		},
+		{
+			name:   "presence bools",
+			args:   []string{"-schema", withOptionsFile, empty},
+			setEnv: func(t *testing.T) {},
+			code:   0,
+			want:   "envcheck: ok, 8 variables checked\n",
+		},
+		{
+			name:   "empty bool",
+			args:   []string{"-schema", schemaFile, empty},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want:   "envcheck: 1 problems\n  APP_DEBUG: invalid bool \"\": invalid syntax\n",
+		},
+		{
+			name:   "required by options",
+			args:   []string{"-schema", allRequiredFile, portOnly},
+			setEnv: func(t *testing.T) {},
+			code:   1,
+			want:   "envcheck: 1 problems\n  DATABASE_URL: required variable is not set\n",
+		},
+		{
+			name: "environment",
+			args: []string{"-schema", schemaFile},
+			setEnv: func(t *testing.T) {
+				t.Setenv("APP_CUSTOM", "x")
+				t.Setenv("APP_PORT", "99999999999999999999")
+			},
+			code: 1,
+			want: "envcheck: 2 problems\n" +
+				"  APP_DB_URL: required variable is not set\n" +
+				"  APP_PORT: invalid int \"99999999999999999999\": value out of range\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.setEnv(t)
+			var stdout, stderr bytes.Buffer
+			code := run(c.args, &stdout, &stderr)
+			if code != c.code {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\nstderr:'%s'\n", c.code, code, stderr.String())
+			}
+			if got := stdout.String(); got != c.want {
+				t.Errorf("\nwant:'%s'\ngot:'%s'\n", c.want, got)
+			}
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	cases := map[string]reflect.Type{
+		"int":                reflect.TypeOf(0),
+		"*time.Duration":     reflect.TypeOf(new(time.Duration)),
+		"[]uint8":            reflect.TypeOf([]byte{}),
+		"map[string][]int":   reflect.TypeOf(map[string][]int{}),
+		"map[string]*string": reflect.TypeOf(map[string]*string{}),
+		"[]netip.Prefix":     reflect.TypeOf([]netip.Prefix{}),
+		"*big.Int":           reflect.TypeOf(new(big.Int)),
+		"[]env.ByteSize":     reflect.TypeOf([]env.ByteSize{}),
+		"main.Custom":        nil,
+		"map[string":         nil,
+		"map[[]int]string":   nil,
+	}
+	for s, want := range cases {
+		got, ok := parseType(s)
+		if ok != (want != nil) || got != want {
+			t.Errorf("%s\nwant:'%v'\ngot:'%v'\n", s, want, got)
+		}
+	}
+}
+
+func write(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
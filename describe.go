@@ -0,0 +1,144 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const descTag = "desc"
+
+// Descriptor describes a variable Unmarshal reads.
+type Descriptor struct {
+	Name        string // variable name
+	Field       string // struct field path, e.g. "DB.URL"
+	Type        string // Go type, e.g. "int" or "[]string"
+	Default     string
+	HasDefault  bool
+	Required    bool
+	Sensitive   bool
+	Enum        []string // allowed values
+	Description string   // from the desc tag
+}
+
+// Describe lists the variables of obj, a struct or a pointer to one (which
+// may be nil), in the order Unmarshal visits them.
+func Describe(obj interface{}, options ...Options) ([]Descriptor, error) {
+	// get default options and merge in any overrides
+	opts := getOptions(options...)
+
+	rt := reflect.TypeOf(obj)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, ErrInvalidType
+	}
+
+	// walk a scratch value, nested pointers are instantiated like Unmarshal does
+	rv := reflect.New(rt).Elem()
+
+	ds := []Descriptor{}
+	err := walkStruct(rv, "", opts, true, func(f field) error {
+		ds = append(ds, describeField(f, opts))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func describeField(f field, opts Options) Descriptor {
+	return Descriptor{
+		Name:        f.Tag.Name,
+		Field:       f.Path,
+		Type:        f.Value.Type().String(),
+		Default:     f.Tag.Default,
+		HasDefault:  f.Tag.HasDefault,
+		Required:    f.Tag.Required || opts.Required && !f.Tag.Optional,
+		Sensitive:   f.Tag.Sensitive,
+		Enum:        f.Tag.Enum,
+		Description: f.StructField.Tag.Get(descTag),
+	}
+}
+
+// WriteMarkdown writes the descriptors as a Markdown table.
+func WriteMarkdown(w io.Writer, ds []Descriptor) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("| Variable | Type | Default | Required | Sensitive | Description |\n")
+	bw.WriteString("|----------|------|---------|----------|-----------|-------------|\n")
+	for _, d := range ds {
+		def := ""
+		if d.HasDefault {
+			def = "`" + markdownEscape(d.displayDefault()) + "`"
+		}
+		fmt.Fprintf(bw, "| `%s` | `%s` | %s | %s | %s | %s |\n",
+			d.Name, markdownEscape(d.Type), def, yesNo(d.Required), yesNo(d.Sensitive), markdownEscape(d.Description))
+	}
+	return bw.Flush()
+}
+
+// WriteExample writes the descriptors as a commented .env.example file.
+// Required variables are left empty, optional variables are commented out
+// and sensitive defaults are not written.
+func WriteExample(w io.Writer, ds []Descriptor) error {
+	bw := bufio.NewWriter(w)
+	for i, d := range ds {
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		if d.Description != "" {
+			fmt.Fprintf(bw, "# %s\n", strings.ReplaceAll(d.Description, "\n", "\n# "))
+		}
+		fmt.Fprintf(bw, "# %s\n", strings.Join(d.attributes(), ", "))
+
+		switch {
+		case d.HasDefault && !d.Sensitive:
+			fmt.Fprintf(bw, "%s=%s\n", d.Name, dotenvQuote(d.Default))
+		case d.Required || d.HasDefault:
+			fmt.Fprintf(bw, "%s=\n", d.Name)
+		default:
+			fmt.Fprintf(bw, "# %s=\n", d.Name)
+		}
+	}
+	return bw.Flush()
+}
+
+// attributes summarises the descriptor, e.g. ["int", "required"]
+func (d Descriptor) attributes() []string {
+	attrs := []string{d.Type}
+	if d.Required {
+		attrs = append(attrs, "required")
+	}
+	if len(d.Enum) != 0 {
+		attrs = append(attrs, "one of "+strings.Join(d.Enum, "|"))
+	}
+	if d.HasDefault {
+		attrs = append(attrs, "default "+d.displayDefault())
+	}
+	if d.Sensitive {
+		attrs = append(attrs, "sensitive")
+	}
+	return attrs
+}
+
+func (d Descriptor) displayDefault() string {
+	if d.Sensitive {
+		return redact(d.Default)
+	}
+	return d.Default
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
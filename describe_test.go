@@ -0,0 +1,153 @@
+package env_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+type describeConfig struct {
+	Port     int           `env:"PORT,default=8080" desc:"HTTP listen port"`
+	DB       *describeDB   `desc:"ignored, structs are recursed into"`
+	LogLevel string        `env:"LOG_LEVEL,optional"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Ignored  string        `env:"-"`
+}
+
+type describeDB struct {
+	URL string `env:"DATABASE_URL,required,sensitive" desc:"Postgres connection | URL"`
+}
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		name string
+		obj  interface{}
+		opts env.Options
+		err  error
+		want []env.Descriptor
+	}{
+		{
+			name: "object is nil",
+			obj:  nil,
+			opts: env.Options{},
+			err:  env.ErrInvalidType,
+			want: nil,
+		},
+		{
+			name: "nil pointer to struct",
+			obj:  (*describeConfig)(nil),
+			opts: env.Options{},
+			err:  nil,
+			want: []env.Descriptor{
+				{Name: "PORT", Field: "Port", Type: "int", Default: "8080", HasDefault: true, Description: "HTTP listen port"},
+				{Name: "DATABASE_URL", Field: "DB.URL", Type: "string", Required: true, Sensitive: true, Description: "Postgres connection | URL"},
+				{Name: "LOG_LEVEL", Field: "LogLevel", Type: "string"},
+				{Name: "TIMEOUT", Field: "Timeout", Type: "time.Duration"},
+			},
+		},
+		{
+			name: "required option",
+			obj:  describeConfig{},
+			opts: env.Options{Required: true},
+			err:  nil,
+			want: []env.Descriptor{
+				{Name: "PORT", Field: "Port", Type: "int", Default: "8080", HasDefault: true, Required: true, Description: "HTTP listen port"},
+				{Name: "DATABASE_URL", Field: "DB.URL", Type: "string", Required: true, Sensitive: true, Description: "Postgres connection | URL"},
+				{Name: "LOG_LEVEL", Field: "LogLevel", Type: "string"},
+				{Name: "TIMEOUT", Field: "Timeout", Type: "time.Duration", Required: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := env.Describe(c.obj, c.opts)
+			if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDescriptorRenderers(t *testing.T) {
+	ds, err := env.Describe(&describeConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds = append(ds, env.Descriptor{Name: "API_KEY", Type: "string", Default: "abc", HasDefault: true, Sensitive: true})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := env.WriteMarkdown(&buf, ds); err != nil {
+			t.Fatal(err)
+		}
+		want := "| Variable | Type | Default | Required | Sensitive | Description |\n" +
+			"|----------|------|---------|----------|-----------|-------------|\n" +
+			"| `PORT` | `int` | `8080` | no | no | HTTP listen port |\n" +
+			"| `DATABASE_URL` | `string` |  | yes | yes | Postgres connection \\| URL |\n" +
+			"| `LOG_LEVEL` | `string` |  | no | no |  |\n" +
+			"| `TIMEOUT` | `time.Duration` |  | no | no |  |\n" +
+			"| `API_KEY` | `string` | `<redacted len=3>` | no | yes |  |\n"
+		if got := buf.String(); got != want {
+			t.Errorf("\nwant:'%s'\ngot:'%s'\n", want, got)
+		}
+	})
+
+	t.Run("example", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := env.WriteExample(&buf, ds); err != nil {
+			t.Fatal(err)
+		}
+		want := "# HTTP listen port\n" +
+			"# int, default 8080\n" +
+			"PORT=8080\n" +
+			"\n" +
+			"# Postgres connection | URL\n" +
+			"# string, required, sensitive\n" +
+			"DATABASE_URL=\n" +
+			"\n" +
+			"# string\n" +
+			"# LOG_LEVEL=\n" +
+			"\n" +
+			"# time.Duration\n" +
+			"# TIMEOUT=\n" +
+			"\n" +
+			"# string, default <redacted len=3>, sensitive\n" +
+			"API_KEY=\n"
+		if got := buf.String(); got != want {
+			t.Errorf("\nwant:'%s'\ngot:'%s'\n", want, got)
+		}
+	})
+}
+
+func TestUsage(t *testing.T) {
+	var cfg struct {
+		Port     int    `env:"PORT,default=8080" desc:"HTTP listen port"`
+		Host     string `env:"HOST,default=localhost"`
+		URL      string `env:"DATABASE_URL,required" desc:"Postgres connection URL"`
+		Password string `env:"PASSWORD,sensitive,default=hunter2"`
+	}
+	src := env.MapSource{"PORT": "80", "PASSWORD": "secret"}
+
+	var buf bytes.Buffer
+	if err := env.Usage(&buf, &cfg, env.Options{Source: src}); err != nil {
+		t.Fatal(err)
+	}
+	want := "  PORT int\n" +
+		"    \tHTTP listen port (default 8080, set)\n" +
+		"  HOST string\n" +
+		"    \t(default \"localhost\", not set)\n" +
+		"  DATABASE_URL string\n" +
+		"    \tPostgres connection URL (required, not set)\n" +
+		"  PASSWORD string\n" +
+		"    \t(default <redacted len=7>, sensitive, set)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("\nwant:'%s'\ngot:'%s'\n", want, got)
+	}
+}
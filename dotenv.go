@@ -0,0 +1,323 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DotenvError reports a syntax error in a dotenv file.
+type DotenvError struct {
+	File string // empty when parsed from a reader
+	Line int
+	Msg  string
+}
+
+func (e *DotenvError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("env: dotenv %s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("env: dotenv line %d: %s", e.Line, e.Msg)
+}
+
+// ParseDotenv parses dotenv syntax:
+//
+//	# comments and blank lines are ignored
+//	export KEY=value          # the export prefix is optional
+//	KEY='literal, no escapes or ${REFERENCES}'
+//	KEY="escapes \n \t \" \\ \$ and ${REFERENCES}"
+//	KEY="quoted values may
+//	span several lines"
+//
+// ${VAR} references resolve against variables defined earlier in the
+// input and then the process environment.
+func ParseDotenv(r io.Reader) (MapSource, error) {
+	return parseDotenv("", r)
+}
+
+// ReadDotenv parses the named files into a single MapSource, a variable
+// defined in an earlier file takes precedence over a later one.
+func ReadDotenv(filenames ...string) (MapSource, error) {
+	vars := MapSource{}
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		fileVars, err := parseDotenv(filename, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			if _, ok := vars[k]; !ok {
+				vars[k] = v
+			}
+		}
+	}
+	return vars, nil
+}
+
+// LoadDotenv reads the named files and sets their variables in the process
+// environment. Variables that are already set are only replaced when
+// override is true.
+func LoadDotenv(override bool, filenames ...string) error {
+	vars, err := ReadDotenv(filenames...)
+	if err != nil {
+		return err
+	}
+	for _, k := range vars.Keys() {
+		if _, ok := os.LookupEnv(k); ok && !override {
+			continue
+		}
+		if err := os.Setenv(k, vars[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseDotenv(filename string, r io.Reader) (MapSource, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotenvParser{
+		file: filename,
+		src:  string(b),
+		line: 1,
+		vars: MapSource{},
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+type dotenvParser struct {
+	file string
+	src  string
+	pos  int
+	line int
+	vars MapSource
+}
+
+func (p *dotenvParser) errorf(line int, format string, args ...interface{}) error {
+	return &DotenvError{File: p.file, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *dotenvParser) parse() error {
+	for {
+		for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+			p.next()
+		}
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if err := p.parseAssignment(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *dotenvParser) parseAssignment() error {
+	line := p.line
+	key := p.parseKey()
+	if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipBlank()
+		key = p.parseKey()
+	}
+	if key == "" {
+		return p.errorf(line, "invalid variable name")
+	}
+
+	p.skipBlank()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(line, "expected '=' after %s", key)
+	}
+	p.next()
+	p.skipBlank()
+
+	var val string
+	var err error
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		val, err = p.parseSingleQuoted()
+	case p.peek() == '"':
+		val, err = p.parseDoubleQuoted()
+	default:
+		val, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return err
+	}
+
+	p.vars[key] = val
+	return nil
+}
+
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' ||
+			p.pos > start && (c == '.' || c >= '0' && c <= '9') {
+			p.next()
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// parseTrailer allows whitespace and a comment after a quoted value
+func (p *dotenvParser) parseTrailer() error {
+	p.skipBlank()
+	if p.eof() {
+		return nil
+	}
+	switch p.peek() {
+	case '#':
+		p.skipLine()
+	case '\r', '\n':
+		p.next()
+	default:
+		return p.errorf(p.line, "unexpected character %q after quoted value", p.peek())
+	}
+	return nil
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+	if p.eof() {
+		return "", p.errorf(line, "unterminated single quoted value")
+	}
+	val := p.src[start:p.pos]
+	p.next()
+	return val, p.parseTrailer()
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated double quoted value")
+		}
+		c := p.next()
+		switch c {
+		case '"':
+			return sb.String(), p.parseTrailer()
+		case '\\':
+			if p.eof() {
+				return "", p.errorf(line, "unterminated double quoted value")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$', '\'':
+				sb.WriteByte(e)
+			default:
+				// unknown escapes are kept verbatim
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+			}
+		case '$':
+			if err := p.expandReference(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquoted() (string, error) {
+	var sb strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.next()
+		switch {
+		case c == '#' && (sb.Len() == 0 || strings.HasSuffix(sb.String(), " ") || strings.HasSuffix(sb.String(), "\t")):
+			// inline comment
+			p.skipLine()
+			return strings.TrimSpace(sb.String()), nil
+		case c == '$':
+			if err := p.expandReference(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// expandReference is called after a '$', a ${VAR} reference is replaced by
+// its value and a lone '$' is kept
+func (p *dotenvParser) expandReference(sb *strings.Builder) error {
+	if p.eof() || p.peek() != '{' {
+		sb.WriteByte('$')
+		return nil
+	}
+	line := p.line
+	p.next()
+	end := strings.IndexAny(p.src[p.pos:], "}\n")
+	if end < 0 || p.src[p.pos+end] != '}' {
+		return p.errorf(line, "unterminated variable reference")
+	}
+	name := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	if name == "" {
+		return p.errorf(line, "empty variable reference")
+	}
+	if v, ok := p.vars[name]; ok {
+		sb.WriteString(v)
+	} else {
+		sb.WriteString(os.Getenv(name))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package env_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+func TestParseDotenv(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		setEnv setEnv
+		err    error
+		want   env.MapSource
+	}{
+		{
+			name:   "empty input",
+			input:  "",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{},
+		},
+		{
+			name:   "comments blank lines and export",
+			input:  "# comment\n\nexport ONE=1\nTWO = 2 # inline comment\nexport=3\n",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{"ONE": "1", "TWO": "2", "export": "3"},
+		},
+		{
+			name:   "unquoted values keep inner spaces and hashes",
+			input:  "URL=http://host/#anchor\nMSG= hello world \r\nEMPTY=\nCOMMENT=#\n",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{"URL": "http://host/#anchor", "MSG": "hello world", "EMPTY": "", "COMMENT": ""},
+		},
+		{
+			name:   "single quotes are literal",
+			input:  `ONE='a \n ${TWO} # b'` + "\n",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{"ONE": `a \n ${TWO} # b`},
+		},
+		{
+			name:   "double quotes with escapes",
+			input:  `ONE="a\nb\t\"c\" \\ \$ \d" # comment` + "\n",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{"ONE": "a\nb\t\"c\" \\ $ \\d"},
+		},
+		{
+			name:   "multi-line quoted values",
+			input:  "ONE=\"first\nsecond\"\nTWO='third\nfourth'\nTHREE=3",
+			setEnv: func(t *testing.T) {},
+			err:    nil,
+			want:   env.MapSource{"ONE": "first\nsecond", "TWO": "third\nfourth", "THREE": "3"},
+		},
+		{
+			name:  "references resolve file then environment",
+			input: "HOST=localhost\nURL=http://${HOST}:${PORT}/\nQUOTED=\"${HOST}\"\nMISSING=${NOPE}x\nCOST=$5",
+			setEnv: func(t *testing.T) {
+				t.Setenv("PORT", "8080")
+			},
+			err: nil,
+			want: env.MapSource{
+				"HOST":    "localhost",
+				"URL":     "http://localhost:8080/",
+				"QUOTED":  "localhost",
+				"MISSING": "x",
+				"COST":    "$5",
+			},
+		},
+		{
+			name:   "missing equals",
+			input:  "ONE=1\nTWO\n",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 2: expected '=' after TWO"),
+			want:   nil,
+		},
+		{
+			name:   "invalid name",
+			input:  "1ONE=1\n",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 1: invalid variable name"),
+			want:   nil,
+		},
+		{
+			name:   "unterminated double quote",
+			input:  "ONE=1\nTWO=\"open\n\nTHREE=3\n",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 2: unterminated double quoted value"),
+			want:   nil,
+		},
+		{
+			name:   "unterminated single quote",
+			input:  "ONE='open",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 1: unterminated single quoted value"),
+			want:   nil,
+		},
+		{
+			name:   "garbage after quoted value",
+			input:  "ONE=\"a\" b\n",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 1: unexpected character 'b' after quoted value"),
+			want:   nil,
+		},
+		{
+			name:   "unterminated reference",
+			input:  "ONE=${TWO\n",
+			setEnv: func(t *testing.T) {},
+			err:    fmt.Errorf("env: dotenv line 1: unterminated variable reference"),
+			want:   nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.setEnv(t)
+			got, err := env.ParseDotenv(strings.NewReader(c.input))
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDotenvFiles(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	base := filepath.Join(dir, ".env")
+	writeFile(t, local, "HOST=local\n")
+	writeFile(t, base, "HOST=base\nPORT=8080\n")
+
+	t.Run("ReadDotenv first file wins", func(t *testing.T) {
+		got, err := env.ReadDotenv(local, base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := env.MapSource{"HOST": "local", "PORT": "8080"}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+
+	t.Run("ReadDotenv error names the file", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.env")
+		writeFile(t, bad, "OK=1\nBAD\n")
+		_, err := env.ReadDotenv(bad)
+		want := fmt.Sprintf("env: dotenv %s:2: expected '=' after BAD", bad)
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("LoadDotenv keeps existing variables", func(t *testing.T) {
+		t.Setenv("HOST", "existing")
+		t.Setenv("PORT", "")
+		os.Unsetenv("PORT")
+		if err := env.LoadDotenv(false, base); err != nil {
+			t.Fatal(err)
+		}
+		if got := os.Getenv("HOST"); got != "existing" {
+			t.Errorf("\nwant:'existing'\ngot:'%#v'\n", got)
+		}
+		if got := os.Getenv("PORT"); got != "8080" {
+			t.Errorf("\nwant:'8080'\ngot:'%#v'\n", got)
+		}
+	})
+
+	t.Run("LoadDotenv override", func(t *testing.T) {
+		t.Setenv("HOST", "existing")
+		t.Setenv("PORT", "")
+		os.Unsetenv("PORT")
+		if err := env.LoadDotenv(true, base); err != nil {
+			t.Fatal(err)
+		}
+		if got := os.Getenv("HOST"); got != "base" {
+			t.Errorf("\nwant:'base'\ngot:'%#v'\n", got)
+		}
+	})
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
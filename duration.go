@@ -0,0 +1,177 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const durationFormats = "want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var errDurationRange = errors.New("out of range")
+
+// parseDuration parses v with time.ParseDuration, or with the extended
+// syntax when extended is set
+func parseDuration(v string, extended bool) (time.Duration, error) {
+	if !extended {
+		return time.ParseDuration(v)
+	}
+
+	s, neg := v, false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s, neg = s[1:], s[0] == '-'
+	}
+
+	var d time.Duration
+	var err error
+	if strings.HasPrefix(s, "P") {
+		d, err = parseISODuration(s[1:])
+	} else {
+		d, err = parseUnitDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %v (%s)", v, err, durationFormats)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseUnitDuration parses the time.ParseDuration syntax with the added
+// units d (24h) and w (7d), e.g. "2w3d12h"
+func parseUnitDuration(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+
+	var total time.Duration
+	for s != "" {
+		// a number, e.g. "1", "1.5" or ".5", then a unit
+		i := 0
+		for i < len(s) && (s[i] == '.' || '0' <= s[i] && s[i] <= '9') {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || '9' < s[j]) {
+			j++
+		}
+		num, unit := s[:i], s[i:j]
+		s = s[j:]
+
+		if num == "" || num == "." || strings.Count(num, ".") > 1 {
+			return 0, fmt.Errorf("invalid number before %q", unit)
+		}
+
+		var d time.Duration
+		var err error
+		switch unit {
+		case "d":
+			d, err = scaleDuration(num, "h", 24)
+		case "w":
+			d, err = scaleDuration(num, "h", 7*24)
+		case "ns", "us", "µs", "μs", "ms", "s", "m", "h":
+			d, err = scaleDuration(num, unit, 1)
+		case "":
+			return 0, fmt.Errorf("missing unit after %q", num)
+		default:
+			return 0, fmt.Errorf("unknown unit %q", unit)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if total, err = addDuration(total, d); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// parseISODuration parses an ISO 8601 duration after the leading P, e.g.
+// "1DT12H" or "2W". Years and months have no fixed length and are rejected.
+func parseISODuration(s string) (time.Duration, error) {
+	date, tm, hasTime := strings.Cut(s, "T")
+	if date == "" && !hasTime || hasTime && tm == "" {
+		return 0, errors.New("no components after P or T")
+	}
+
+	var total time.Duration
+	for _, part := range []struct {
+		s     string
+		units string // allowed designators, in order
+	}{{date, "YMWD"}, {tm, "HMS"}} {
+		s, next := part.s, 0
+		for s != "" {
+			i := 0
+			for i < len(s) && ('0' <= s[i] && s[i] <= '9' || s[i] == '.' || s[i] == ',') {
+				i++
+			}
+			if i == len(s) {
+				return 0, fmt.Errorf("missing designator after %q", s)
+			}
+			num, unit := strings.Replace(s[:i], ",", ".", 1), s[i]
+			s = s[i+1:]
+
+			if num == "" || num[0] == '.' || num[len(num)-1] == '.' || strings.Count(num, ".") > 1 {
+				return 0, fmt.Errorf("invalid number before %q", unit)
+			}
+			k := strings.IndexByte(part.units[next:], unit)
+			if k < 0 {
+				return 0, fmt.Errorf("unexpected designator %q", unit)
+			}
+			next += k + 1
+
+			var d time.Duration
+			var err error
+			switch {
+			case part.units == "HMS" && unit == 'H':
+				d, err = scaleDuration(num, "h", 1)
+			case part.units == "HMS" && unit == 'M':
+				d, err = scaleDuration(num, "m", 1)
+			case unit == 'S':
+				d, err = scaleDuration(num, "s", 1)
+			case unit == 'D':
+				d, err = scaleDuration(num, "h", 24)
+			case unit == 'W':
+				d, err = scaleDuration(num, "h", 7*24)
+			default: // Y and M in the date part
+				return 0, errors.New("years and months have no fixed length")
+			}
+			if err != nil {
+				return 0, err
+			}
+			if total, err = addDuration(total, d); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// scaleDuration returns factor times the duration num unit, num has been
+// checked so time.ParseDuration only fails on overflow
+func scaleDuration(num string, unit string, factor int64) (time.Duration, error) {
+	d, err := time.ParseDuration(num + unit)
+	if err != nil {
+		return 0, errDurationRange
+	}
+	if d > time.Duration(math.MaxInt64/factor) {
+		return 0, errDurationRange
+	}
+	return d * time.Duration(factor), nil
+}
+
+func addDuration(a, b time.Duration) (time.Duration, error) {
+	if a > math.MaxInt64-b {
+		return 0, errDurationRange
+	}
+	return a + b, nil
+}
@@ -0,0 +1,173 @@
+package env_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+func TestExtendedDurations(t *testing.T) {
+	cases := []struct {
+		value string
+		err   error
+		want  time.Duration
+	}{
+		{value: "0", err: nil, want: 0},
+		{value: "1h30m", err: nil, want: 90 * time.Minute},
+		{value: "30d", err: nil, want: 30 * 24 * time.Hour},
+		{value: "2w", err: nil, want: 14 * 24 * time.Hour},
+		{value: "1w2d3h4m5s6ms", err: nil, want: 9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second + 6*time.Millisecond},
+		{value: "1.5d", err: nil, want: 36 * time.Hour},
+		{value: "-.5w", err: nil, want: -84 * time.Hour},
+		{value: "P1DT12H", err: nil, want: 36 * time.Hour},
+		{value: "P2W", err: nil, want: 14 * 24 * time.Hour},
+		{value: "PT1M30.5S", err: nil, want: 90*time.Second + 500*time.Millisecond},
+		{value: "PT0,5H", err: nil, want: 30 * time.Minute},
+		{value: "-P1D", err: nil, want: -24 * time.Hour},
+		{
+			value: "",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'=''] as time.Duration for field 'Duration': invalid duration "": empty duration (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "5 days",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='5 days'] as time.Duration for field 'Duration': invalid duration "5 days": unknown unit " days" (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "30",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='30'] as time.Duration for field 'Duration': invalid duration "30": missing unit after "30" (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "P1M",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='P1M'] as time.Duration for field 'Duration': invalid duration "P1M": years and months have no fixed length (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "PT1H1D",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='PT1H1D'] as time.Duration for field 'Duration': invalid duration "PT1H1D": unexpected designator 'D' (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "P1DT",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='P1DT'] as time.Duration for field 'Duration': invalid duration "P1DT": no components after P or T (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+		{
+			value: "200000w",
+			err:   fmt.Errorf(`env: unable to parse ['DURATION'='200000w'] as time.Duration for field 'Duration': invalid duration "200000w": out of range (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			src := env.MapSource{"DURATION": c.value}
+			obj := &struct {
+				Duration time.Duration `env:"DURATION,extended"`
+			}{}
+			err := env.Unmarshal(obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if obj.Duration != c.want {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, obj.Duration)
+				}
+			}
+		})
+	}
+}
+
+func TestExtendedDurationsOption(t *testing.T) {
+	src := env.MapSource{"RETENTION": "30d", "TIMEOUTS": "1d,PT5M"}
+
+	t.Run("off by default", func(t *testing.T) {
+		_, err := env.AsDuration("RETENTION", env.Options{Source: src})
+		want := `env: unable to parse ['RETENTION'='30d'] as duration`
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("AsDuration", func(t *testing.T) {
+		got, err := env.AsDuration("RETENTION", env.Options{Source: src, ExtendedDurations: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 30 * 24 * time.Hour; got != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+
+	t.Run("AsDuration invalid", func(t *testing.T) {
+		_, err := env.AsDuration("V", env.Options{Source: env.MapSource{"V": "30x"}, ExtendedDurations: true})
+		// the accepted formats are shown once the option is on
+		want := `env: unable to parse ['V'='30x'] as duration: invalid duration "30x": unknown unit "x" (want Go syntax like 1h30m, days and weeks like 30d or 2w, or ISO 8601 like P1DT12H)`
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var cfg struct {
+			Retention time.Duration    `env:"RETENTION"`
+			Timeouts  []*time.Duration `env:"TIMEOUTS"`
+		}
+		if err := env.Unmarshal(&cfg, env.Options{Source: src, ExtendedDurations: true}); err != nil {
+			t.Fatal(err)
+		}
+		want := []*time.Duration{ptrDuration(24 * time.Hour), ptrDuration(5 * time.Minute)}
+		if cfg.Retention != 30*24*time.Hour || !reflect.DeepEqual(want, cfg.Timeouts) {
+			t.Errorf("\ngot:'%#v'\n", cfg)
+		}
+	})
+
+	t.Run("NewSchema", func(t *testing.T) {
+		s, err := env.NewSchema(&struct {
+			Retention time.Duration            `env:"RETENTION,default=30d"`
+			Timeouts  []*time.Duration         `env:"TIMEOUTS"`
+			Limits    map[string]time.Duration `env:"LIMITS,extended"`
+			Name      string                   `env:"NAME"`
+		}{}, env.Options{ExtendedDurations: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"RETENTION": "RETENTION,extended,default=30d",
+			"TIMEOUTS":  "TIMEOUTS,extended",
+			"LIMITS":    "LIMITS,extended",
+			"NAME":      "NAME",
+		}
+		for name, tag := range want {
+			if got := s.Properties[name].Tag; got != tag {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", tag, got)
+			}
+		}
+	})
+}
+
+func TestExtendedDurationSchema(t *testing.T) {
+	s, err := env.NewSchema(&struct {
+		Retention time.Duration `env:"RETENTION,extended"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pattern must agree with the parser
+	re := regexp.MustCompile(s.Properties["RETENTION"].Pattern)
+	for _, v := range []string{
+		"0", "5s", "30d", "1.5w", "-.5d", "1d12h", "P1D", "P2W", "P1W2D", "PT1H", "PT1,5H", "P1DT2H3M4S", "PT3M4.5S", "-P1D",
+		"", "5", "d", "1x", "P", "PT", "P1DT", "P1Y", "P1M", "PT1D", "P1.D", "P.5D", "1d P1D",
+	} {
+		_, err := env.AsDuration("V", env.Options{Source: env.MapSource{"V": v}, ExtendedDurations: true})
+		if want, got := err == nil, re.MatchString(v); want != got {
+			t.Errorf("%q: parser accepts %v, pattern matches %v", v, want, got)
+		}
+	}
+}
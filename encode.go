@@ -0,0 +1,141 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format for WriteVars and Encode.
+type Format int
+
+const (
+	FormatDotenv Format = iota // NAME="value", readable by ParseDotenv
+	FormatShell                // export NAME='value', for POSIX sh
+	FormatDocker               // NAME=value, for docker run --env-file
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatDotenv:
+		return "dotenv"
+	case FormatShell:
+		return "shell"
+	case FormatDocker:
+		return "docker env-file"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Encode writes the tagged fields of obj, as returned by Marshal, to w.
+func Encode(w io.Writer, obj interface{}, f Format, options ...Options) error {
+	vars, err := Marshal(obj, options...)
+	if err != nil {
+		return err
+	}
+	return WriteVars(w, vars, f)
+}
+
+// WriteVars writes one line per variable to w, quoting values as the
+// format requires.
+func WriteVars(w io.Writer, vars Vars, f Format) error {
+	bw := bufio.NewWriter(w)
+	for _, v := range vars {
+		line, err := formatLine(v, f)
+		if err != nil {
+			return err
+		}
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func formatLine(v Var, f Format) (string, error) {
+	switch f {
+	case FormatDotenv:
+		if !isDotenvName(v.Name) {
+			return "", fmt.Errorf("env: invalid %s variable name %q", f, v.Name)
+		}
+		return v.Name + "=" + dotenvQuote(v.Value), nil
+	case FormatShell:
+		if !isShellName(v.Name) {
+			return "", fmt.Errorf("env: invalid %s variable name %q", f, v.Name)
+		}
+		return "export " + v.Name + "=" + shellQuote(v.Value), nil
+	case FormatDocker:
+		// docker env-files have no quoting, each line is taken verbatim
+		if v.Name == "" || strings.ContainsAny(v.Name, "= \t\r\n") || v.Name[0] == '#' {
+			return "", fmt.Errorf("env: invalid %s variable name %q", f, v.Name)
+		}
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return "", fmt.Errorf("env: %s cannot hold the multi-line value of %q", f, v.Name)
+		}
+		return v.Name + "=" + v.Value, nil
+	}
+	return "", fmt.Errorf("env: unknown format %s", f)
+}
+
+// isSafe reports whether s can be written without quotes
+func isSafe(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("_-./:,@%+=", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func dotenvQuote(s string) string {
+	if isSafe(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '"', '$':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// shellQuote wraps s in single quotes, inside which only the quote itself
+// needs escaping: close the quotes, add \' and reopen them
+func shellQuote(s string) string {
+	if s != "" && isSafe(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellName(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isDotenvName matches the names ParseDotenv accepts
+func isDotenvName(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && (c == '.' || c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
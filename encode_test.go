@@ -0,0 +1,142 @@
+package env_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+var encodeVars = env.Vars{
+	{Name: "PLAIN", Value: "http://host:80/path,a@b"},
+	{Name: "EMPTY", Value: ""},
+	{Name: "SPACES", Value: " a b "},
+	{Name: "QUOTES", Value: `it's "quoted"`},
+	{Name: "SPECIAL", Value: `$HOME ${HOME} \ # ;`},
+	{Name: "UNICODE", Value: "héllo"},
+}
+
+func TestWriteVars(t *testing.T) {
+	cases := []struct {
+		name   string
+		vars   env.Vars
+		format env.Format
+		err    error
+		want   string
+	}{
+		{
+			name:   "dotenv",
+			vars:   encodeVars,
+			format: env.FormatDotenv,
+			err:    nil,
+			want: `PLAIN=http://host:80/path,a@b
+EMPTY=
+SPACES=" a b "
+QUOTES="it's \"quoted\""
+SPECIAL="\$HOME \${HOME} \\ # ;"
+UNICODE="héllo"
+`,
+		},
+		{
+			name:   "dotenv multi-line",
+			vars:   env.Vars{{Name: "CERT", Value: "line1\nline2\ttab"}},
+			format: env.FormatDotenv,
+			err:    nil,
+			want:   "CERT=\"line1\\nline2\\ttab\"\n",
+		},
+		{
+			name:   "shell",
+			vars:   encodeVars,
+			format: env.FormatShell,
+			err:    nil,
+			want: `export PLAIN=http://host:80/path,a@b
+export EMPTY=''
+export SPACES=' a b '
+export QUOTES='it'\''s "quoted"'
+export SPECIAL='$HOME ${HOME} \ # ;'
+export UNICODE='héllo'
+`,
+		},
+		{
+			name:   "shell invalid name",
+			vars:   env.Vars{{Name: "A.B", Value: "1"}},
+			format: env.FormatShell,
+			err:    fmt.Errorf(`env: invalid shell variable name "A.B"`),
+			want:   "",
+		},
+		{
+			name:   "docker",
+			vars:   encodeVars,
+			format: env.FormatDocker,
+			err:    nil,
+			want: "PLAIN=http://host:80/path,a@b\n" +
+				"EMPTY=\n" +
+				"SPACES= a b \n" +
+				"QUOTES=it's \"quoted\"\n" +
+				"SPECIAL=$HOME ${HOME} \\ # ;\n" +
+				"UNICODE=héllo\n",
+		},
+		{
+			name:   "docker multi-line",
+			vars:   env.Vars{{Name: "CERT", Value: "line1\nline2"}},
+			format: env.FormatDocker,
+			err:    fmt.Errorf(`env: docker env-file cannot hold the multi-line value of "CERT"`),
+			want:   "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := env.WriteVars(&buf, c.vars, c.format)
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if got := buf.String(); got != c.want {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeDotenvRoundTrip(t *testing.T) {
+	vars := append(env.Vars{{Name: "CERT", Value: "line1\nline2\r\n\x00"}}, encodeVars...)
+
+	var buf bytes.Buffer
+	if err := env.WriteVars(&buf, vars, env.FormatDotenv); err != nil {
+		t.Fatal(err)
+	}
+	got, err := env.ParseDotenv(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := vars.Map(); !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	cfg := struct {
+		Host string   `env:"HOST"`
+		Tags []string `env:"TAGS"`
+	}{Host: "localhost", Tags: []string{"a", "b c"}}
+
+	var buf bytes.Buffer
+	if err := env.Encode(&buf, cfg, env.FormatShell); err != nil {
+		t.Fatal(err)
+	}
+	want := "export HOST=localhost\nexport TAGS='a,b c'\n"
+	if got := buf.String(); got != want {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+	}
+}
@@ -0,0 +1,294 @@
+package env_test
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+// token implements encoding.BinaryMarshaler and BinaryUnmarshaler only
+type token struct {
+	b []byte
+}
+
+func (t *token) UnmarshalBinary(b []byte) error {
+	if len(b) != 4 {
+		return fmt.Errorf("token must be 4 bytes")
+	}
+	t.b = b
+	return nil
+}
+
+func (t token) MarshalBinary() ([]byte, error) {
+	return t.b, nil
+}
+
+// level implements both Unmarshaler and encoding.TextUnmarshaler
+type level string
+
+func (l *level) UnmarshalENV(v string) error {
+	*l = level("env:" + v)
+	return nil
+}
+
+func (l *level) UnmarshalText(b []byte) error {
+	*l = level("text:" + string(b))
+	return nil
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	src := env.MapSource{
+		"ADDR":     "10.0.0.1",
+		"PREFIXES": "10.0.0.0/8,192.168.0.0/16",
+		"BIG":      "123456789012345678901234567890",
+		"TIME":     "2024-02-29T12:30:00Z",
+		"LEVEL":    "debug",
+		"TOKEN":    "AQIDBA==",
+		"BYTES":    "aGVsbG8=",
+		"BAD":      "1.2.3",
+	}
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want interface{}
+	}{
+		{
+			name: "TextUnmarshaler",
+			obj: &struct {
+				Addr netip.Addr `env:"ADDR"`
+				Time time.Time  `env:"TIME"`
+			}{},
+			err: nil,
+			want: &struct {
+				Addr netip.Addr `env:"ADDR"`
+				Time time.Time  `env:"TIME"`
+			}{Addr: netip.MustParseAddr("10.0.0.1"), Time: time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)},
+		},
+		{
+			name: "pointer and slice of TextUnmarshaler",
+			obj: &struct {
+				Big      *big.Int       `env:"BIG"`
+				Prefixes []netip.Prefix `env:"PREFIXES"`
+			}{},
+			err: nil,
+			want: &struct {
+				Big      *big.Int       `env:"BIG"`
+				Prefixes []netip.Prefix `env:"PREFIXES"`
+			}{Big: n, Prefixes: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.0.0/16")}},
+		},
+		{
+			name: "Unmarshaler before TextUnmarshaler",
+			obj: &struct {
+				Level level `env:"LEVEL"`
+			}{},
+			err: nil,
+			want: &struct {
+				Level level `env:"LEVEL"`
+			}{Level: "env:debug"},
+		},
+		{
+			name: "base64 BinaryUnmarshaler",
+			obj: &struct {
+				Token *token `env:"TOKEN,base64"`
+			}{},
+			err: nil,
+			want: &struct {
+				Token *token `env:"TOKEN,base64"`
+			}{Token: &token{[]byte{1, 2, 3, 4}}},
+		},
+		{
+			name: "base64 bytes",
+			obj: &struct {
+				Bytes []byte `env:"BYTES,base64"`
+			}{},
+			err: nil,
+			want: &struct {
+				Bytes []byte `env:"BYTES,base64"`
+			}{Bytes: []byte("hello")},
+		},
+		{
+			name: "BinaryUnmarshaler error",
+			obj: &struct {
+				Token token `env:"BYTES,base64"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['BYTES'='aGVsbG8='] as env_test.token for field 'Token': token must be 4 bytes"),
+			want: nil,
+		},
+		{
+			name: "invalid base64",
+			obj: &struct {
+				Bytes []byte `env:"BAD,base64"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['BAD'='1.2.3'] as []uint8 for field 'Bytes': illegal base64 data at input byte 1"),
+			want: nil,
+		},
+		{
+			name: "TextUnmarshaler error",
+			obj: &struct {
+				Addr netip.Addr `env:"BAD"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['BAD'='1.2.3'] as netip.Addr for field 'Addr': ParseAddr("1.2.3"): IPv4 address too short`),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+}
+
+func TestTextMarshaler(t *testing.T) {
+	type config struct {
+		Addr     netip.Addr      `env:"ADDR"`
+		Prefixes []netip.Prefix  `env:"PREFIXES"`
+		Time     *time.Time      `env:"TIME"`
+		Token    token           `env:"TOKEN,base64"`
+		Bytes    []byte          `env:"BYTES,base64"`
+		Big      *big.Int        `env:"BIG"`
+		Missing  *netip.AddrPort `env:"MISSING"`
+	}
+	ts := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	cfg := config{
+		Addr:     netip.MustParseAddr("::1"),
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		Time:     &ts,
+		Token:    token{[]byte{1, 2, 3, 4}},
+		Bytes:    []byte("hello"),
+		Big:      big.NewInt(42),
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Vars{
+		{Name: "ADDR", Value: "::1"},
+		{Name: "PREFIXES", Value: "10.0.0.0/8"},
+		{Name: "TIME", Value: "2024-02-29T12:30:00Z"},
+		{Name: "TOKEN", Value: "AQIDBA=="},
+		{Name: "BYTES", Value: "aGVsbG8="},
+		{Name: "BIG", Value: "42"},
+	}
+	if !reflect.DeepEqual(want, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, vars)
+	}
+
+	var got config
+	if err := env.Unmarshal(&got, env.Options{Source: vars.Map()}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", cfg, got)
+	}
+}
+
+// loggedDB can be marshaled for logging but is configured field by field
+type loggedDB struct {
+	URL  string `env:"DB_URL"`
+	Pool int    `env:"DB_POOL"`
+}
+
+func (d loggedDB) MarshalText() ([]byte, error) {
+	return []byte("db " + d.URL), nil
+}
+
+// formattedCache only implements Marshaler
+type formattedCache struct {
+	Size int `env:"CACHE_SIZE"`
+}
+
+func (c formattedCache) MarshalENV() (string, error) {
+	return fmt.Sprint(c.Size), nil
+}
+
+// textDB implements UnmarshalText for another format, e.g. TOML
+type textDB struct {
+	Host string `env:"DB_HOST"`
+}
+
+func (d *textDB) UnmarshalText(b []byte) error {
+	d.Host = "text " + string(b)
+	return nil
+}
+
+func TestUntaggedTextUnmarshalersAreWalked(t *testing.T) {
+	type config struct {
+		DB      textDB
+		Replica *textDB
+		Primary textDB `env:"PRIMARY"`
+	}
+	src := env.MapSource{"DB_HOST": "db", "PRIMARY": "primary"}
+
+	var cfg config
+	if err := env.Unmarshal(&cfg, env.Options{Source: src}); err != nil {
+		t.Fatal(err)
+	}
+	want := config{DB: textDB{Host: "db"}, Replica: &textDB{Host: "db"}, Primary: textDB{Host: "text primary"}}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, cfg)
+	}
+}
+
+func TestMarshalOnlyStructsAreWalked(t *testing.T) {
+	type config struct {
+		DB    loggedDB
+		Cache *formattedCache
+	}
+	src := env.MapSource{"DB_URL": "postgres://db", "DB_POOL": "4", "CACHE_SIZE": "10"}
+
+	var cfg config
+	if err := env.Unmarshal(&cfg, env.Options{Source: src}); err != nil {
+		t.Fatal(err)
+	}
+	want := config{DB: loggedDB{URL: "postgres://db", Pool: 4}, Cache: &formattedCache{Size: 10}}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, cfg)
+	}
+
+	s, err := env.NewSchema(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"DB_URL", "DB_POOL", "CACHE_SIZE"} {
+		if _, ok := s.Properties[name]; !ok {
+			t.Errorf("schema is missing %s", name)
+		}
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantVars := env.Vars{
+		{Name: "DB_URL", Value: "postgres://db"},
+		{Name: "DB_POOL", Value: "4"},
+		{Name: "CACHE_SIZE", Value: "10"},
+	}
+	if !reflect.DeepEqual(wantVars, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", wantVars, vars)
+	}
+}
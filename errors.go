@@ -0,0 +1,202 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Sentinels for use with errors.Is, e.g. errors.Is(err, env.ErrNotFound)
+// is true for both NotFoundError and RequiredError.
+var (
+	ErrNotFound        = errors.New("env: variable not found")
+	ErrParse           = errors.New("env: unable to parse variable")
+	ErrUnsupportedType = errors.New("env: unsupported type")
+)
+
+// NotFoundError is returned by the As* helpers when a variable is not set.
+type NotFoundError struct {
+	Var string // variable name
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("env: '%s' not found", e.Var)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// RequiredError is returned when a required variable is not set.
+type RequiredError struct {
+	Var   string // variable name
+	Field string // struct field path, e.g. "DB.URL"
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("env: '%s' is required by field '%s'", e.Var, e.Field)
+}
+
+func (e *RequiredError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ParseError is returned when a value cannot be parsed into its type.
+type ParseError struct {
+	Var     string // variable name
+	Field   string // struct field path, empty for the As* helpers
+	Type    string // target type, e.g. "int" or "[]string"
+	Value   string // the value that failed to parse
+	Default bool   // the value came from the default= tag option
+	Err     error  // the underlying error, e.g. a *strconv.NumError
+
+	// Sensitive is set when Value and Err have been masked, see the
+	// sensitive tag option and Options.Redact
+	Sensitive bool
+
+	// detailed is set by the As* helpers when an opt-in syntax is on, its
+	// errors list the accepted formats
+	detailed bool
+}
+
+func (e *ParseError) Error() string {
+	kind := ""
+	if e.Default {
+		kind = "default "
+	}
+	value := "'" + e.Value + "'"
+	if e.Sensitive {
+		value = e.Value
+	}
+	msg := fmt.Sprintf("env: unable to parse %s['%s'=%s] as %s", kind, e.Var, value, e.Type)
+	if e.Field != "" {
+		msg += fmt.Sprintf(" for field '%s'", e.Field)
+	}
+	// the As* helpers keep their short message unless an opt-in syntax is
+	// on, the cause is in Err
+	if e.Err != nil && (e.Field != "" || e.detailed) {
+		msg += ": " + reason(e.Err)
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// redact masks the value. The underlying error may quote any part of it,
+// e.g. a single list element, so only a strconv reason is kept and other
+// messages are replaced.
+func (e *ParseError) redact() {
+	if e.Sensitive {
+		return
+	}
+	mask := redact(e.Value)
+	if ne, ok := e.Err.(*strconv.NumError); ok {
+		masked := *ne
+		masked.Num = mask
+		e.Err = &masked
+	} else if e.Err != nil {
+		e.Err = &redactedError{msg: "invalid value", err: e.Err}
+	}
+	e.Value = mask
+	e.Sensitive = true
+}
+
+// redact hides a value, only its length is kept
+func redact(v string) string {
+	return fmt.Sprintf("<redacted len=%d>", len(v))
+}
+
+// redactedError replaces the message of an error that may quote a
+// sensitive value while keeping it available to errors.Is and errors.As
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// UnsupportedTypeError is returned for fields of a type env cannot parse.
+type UnsupportedTypeError struct {
+	Field string // struct field path, empty for the As* helpers
+	Type  reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("env: unsupported type '%s'", e.Type)
+	}
+	return fmt.Sprintf("env: unsupported type '%s' for field '%s'", e.Type, e.Field)
+}
+
+func (e *UnsupportedTypeError) Is(target error) bool {
+	return target == ErrUnsupportedType
+}
+
+// reason drops the parts of strconv errors ParseError already reports
+func reason(err error) string {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return ne.Err.Error()
+	}
+	return err.Error()
+}
+
+// Errors holds every problem Unmarshal found, it supports errors.Is and
+// errors.As against any of them.
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("env: %d errors:\n\t%s", len(e), strings.Join(msgs, "\n\t"))
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add appends err, flattening nested Errors
+func (e *Errors) add(err error) {
+	if errs, ok := err.(Errors); ok {
+		*e = append(*e, errs...)
+		return
+	}
+	*e = append(*e, err)
+}
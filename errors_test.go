@@ -0,0 +1,320 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+type brokenConfig struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT"`
+	DB   struct {
+		URL     string        `env:"DB_URL,required"`
+		Retries uint8         `env:"DB_RETRIES"`
+		Timeout time.Duration `env:"DB_TIMEOUT,default=soon"`
+	}
+}
+
+func TestErrors(t *testing.T) {
+	src := env.MapSource{"PORT": "http", "DB_RETRIES": "300"}
+
+	t.Run("every problem is collected", func(t *testing.T) {
+		var cfg brokenConfig
+		err := env.Unmarshal(&cfg, env.Options{Source: src})
+
+		var errs env.Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("\nwant:'env.Errors'\ngot:'%#v'\n", err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		want := []string{
+			`env: 'HOST' is required by field 'Host'`,
+			`env: unable to parse ['PORT'='http'] as int for field 'Port': invalid syntax`,
+			`env: 'DB_URL' is required by field 'DB.URL'`,
+			`env: unable to parse ['DB_RETRIES'='300'] as uint8 for field 'DB.Retries': value out of range`,
+			`env: unable to parse default ['DB_TIMEOUT'='soon'] as time.Duration for field 'DB.Timeout': time: invalid duration "soon"`,
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+
+	t.Run("errors.As finds a specific error", func(t *testing.T) {
+		var cfg brokenConfig
+		err := env.Unmarshal(&cfg, env.Options{Source: src})
+
+		var required *env.RequiredError
+		if !errors.As(err, &required) || required.Var != "HOST" || required.Field != "Host" {
+			t.Errorf("\nwant:'HOST required'\ngot:'%#v'\n", required)
+		}
+		var parse *env.ParseError
+		if !errors.As(err, &parse) || parse.Var != "PORT" || parse.Value != "http" {
+			t.Errorf("\nwant:'PORT parse error'\ngot:'%#v'\n", parse)
+		}
+	})
+
+	t.Run("fail fast returns the first error", func(t *testing.T) {
+		var cfg brokenConfig
+		err := env.Unmarshal(&cfg, env.Options{Source: src, FailFast: true})
+
+		want := &env.RequiredError{Var: "HOST", Field: "Host"}
+		if !reflect.DeepEqual(want, err) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("aggregate message lists every problem", func(t *testing.T) {
+		var cfg struct {
+			A string `env:"A,required"`
+			B string `env:"B,required"`
+		}
+		err := env.Unmarshal(&cfg, env.Options{Source: env.MapSource{}})
+
+		want := "env: 2 errors:\n\tenv: 'A' is required by field 'A'\n\tenv: 'B' is required by field 'B'"
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+}
+
+func TestErrorTypes(t *testing.T) {
+	src := env.MapSource{"GARBAGE": "abc"}
+
+	cases := []struct {
+		name   string
+		run    func() error
+		is     []error
+		isNot  []error
+		target interface{}
+		want   interface{}
+	}{
+		{
+			name:   "AsInt not found",
+			run:    func() error { _, err := env.AsInt("MISSING", 64, env.Options{Source: src}); return err },
+			is:     []error{env.ErrNotFound},
+			isNot:  []error{env.ErrParse},
+			target: new(*env.NotFoundError),
+			want:   &env.NotFoundError{Var: "MISSING"},
+		},
+		{
+			name:   "AsInt garbage",
+			run:    func() error { _, err := env.AsInt("GARBAGE", 64, env.Options{Source: src}); return err },
+			is:     []error{env.ErrParse, strconv.ErrSyntax},
+			isNot:  []error{env.ErrNotFound},
+			target: new(*strconv.NumError),
+			want:   &strconv.NumError{Func: "ParseInt", Num: "abc", Err: strconv.ErrSyntax},
+		},
+		{
+			name: "Unmarshal required",
+			run: func() error {
+				var cfg struct {
+					URL string `env:"URL,required"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			is:     []error{env.ErrNotFound},
+			isNot:  []error{env.ErrParse, env.ErrUnsupportedType},
+			target: new(*env.RequiredError),
+			want:   &env.RequiredError{Var: "URL", Field: "URL"},
+		},
+		{
+			name: "Unmarshal garbage",
+			run: func() error {
+				var cfg struct {
+					Int int `env:"GARBAGE"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			is:     []error{env.ErrParse},
+			isNot:  []error{env.ErrNotFound},
+			target: new(*env.ParseError),
+			want: &env.ParseError{
+				Var:   "GARBAGE",
+				Field: "Int",
+				Type:  "int",
+				Value: "abc",
+				Err:   &strconv.NumError{Func: "ParseInt", Num: "abc", Err: strconv.ErrSyntax},
+			},
+		},
+		{
+			name: "Unmarshal unsupported type",
+			run: func() error {
+				var cfg struct {
+					Chan chan int `env:"GARBAGE"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			is:     []error{env.ErrUnsupportedType},
+			isNot:  []error{env.ErrParse},
+			target: new(*env.UnsupportedTypeError),
+			want:   &env.UnsupportedTypeError{Field: "Chan", Type: reflect.TypeOf(make(chan int))},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.run()
+			for _, target := range c.is {
+				if !errors.Is(err, target) {
+					t.Errorf("\nwant errors.Is:'%v'\ngot:'%#v'\n", target, err)
+				}
+			}
+			for _, target := range c.isNot {
+				if errors.Is(err, target) {
+					t.Errorf("\nwant not errors.Is:'%v'\ngot:'%#v'\n", target, err)
+				}
+			}
+			if !errors.As(err, c.target) {
+				t.Fatalf("\nwant errors.As:'%T'\ngot:'%#v'\n", c.target, err)
+			}
+			got := reflect.ValueOf(c.target).Elem().Interface()
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+			}
+		})
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	src := env.MapSource{
+		"PIN":     "hunter2",
+		"TIMEOUT": "s3cret",
+		"KEYS":    "1s,hunter2",
+		"TIMES":   "2024-01-02,hunter2",
+		"FLAGS":   "yes,hunter2",
+		"LIMITS":  "a:1,b:hunter2",
+	}
+
+	cases := []struct {
+		name string
+		run  func() error
+		want string
+	}{
+		{
+			name: "sensitive tag option",
+			run: func() error {
+				var cfg struct {
+					Pin int `env:"PIN,sensitive"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['PIN'=<redacted len=7>] as int for field 'Pin': invalid syntax",
+		},
+		{
+			name: "secret tag option masks value in underlying error",
+			run: func() error {
+				var cfg struct {
+					Timeout time.Duration `env:"TIMEOUT,secret"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['TIMEOUT'=<redacted len=6>] as time.Duration for field 'Timeout': invalid value",
+		},
+		{
+			name: "sensitive slice masks the element in underlying error",
+			run: func() error {
+				var cfg struct {
+					Keys []time.Duration `env:"KEYS,sensitive"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['KEYS'=<redacted len=10>] as []time.Duration for field 'Keys': invalid value",
+		},
+		{
+			name: "sensitive extended duration slice",
+			run: func() error {
+				var cfg struct {
+					Keys []time.Duration `env:"KEYS,sensitive,extended"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['KEYS'=<redacted len=10>] as []time.Duration for field 'Keys': invalid value",
+		},
+		{
+			name: "sensitive time slice",
+			run: func() error {
+				var cfg struct {
+					Times []time.Time `env:"TIMES,sensitive,layout=DateOnly"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['TIMES'=<redacted len=18>] as []time.Time for field 'Times': invalid value",
+		},
+		{
+			name: "sensitive lenient bool slice",
+			run: func() error {
+				var cfg struct {
+					Flags []bool `env:"FLAGS,sensitive,lenient"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['FLAGS'=<redacted len=11>] as []bool for field 'Flags': invalid value",
+		},
+		{
+			name: "sensitive map",
+			run: func() error {
+				var cfg struct {
+					Limits map[string]int `env:"LIMITS,sensitive"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse ['LIMITS'=<redacted len=13>] as map[string]int for field 'Limits': invalid syntax",
+		},
+		{
+			name: "sensitive default",
+			run: func() error {
+				var cfg struct {
+					Key int `env:"KEY,sensitive,default=abc"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src})
+			},
+			want: "env: unable to parse default ['KEY'=<redacted len=3>] as int for field 'Key': invalid syntax",
+		},
+		{
+			name: "Redact option",
+			run: func() error {
+				var cfg struct {
+					Pin int `env:"PIN"`
+				}
+				return env.Unmarshal(&cfg, env.Options{Source: src, Redact: true})
+			},
+			want: "env: unable to parse ['PIN'=<redacted len=7>] as int for field 'Pin': invalid syntax",
+		},
+		{
+			name: "AsInt Redact option",
+			run: func() error {
+				_, err := env.AsInt("PIN", 64, env.Options{Source: src, Redact: true})
+				return err
+			},
+			want: "env: unable to parse ['PIN'=<redacted len=7>] as int[64]",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.run()
+			if err == nil || err.Error() != c.want {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, err)
+			}
+			if err != nil && strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("\nwant:'redacted error'\ngot:'%#v'\n", err)
+			}
+			var ne *strconv.NumError
+			if errors.As(err, &ne) && !strings.HasPrefix(ne.Num, "<redacted len=") {
+				t.Errorf("\nwant:'redacted NumError'\ngot:'%#v'\n", ne)
+			}
+			if !errors.Is(err, env.ErrParse) {
+				t.Errorf("\nwant errors.Is:'%v'\ngot:'%#v'\n", env.ErrParse, err)
+			}
+		})
+	}
+}
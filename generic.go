@@ -0,0 +1,41 @@
+package env
+
+import "reflect"
+
+// As looks up a variable and parses it into T exactly as Unmarshal would
+// parse a field of type T, e.g. As[[]int]("PORTS") or As[*url.URL]("URL")
+// for a type implementing Unmarshaler.
+func As[T any](name string, options ...Options) (T, error) {
+	var v T
+	opts := getOptions(options...)
+	val, err := lookup(name, opts)
+	if err != nil {
+		return v, err
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	err = setValue(rv, val, tag{Name: name}, opts)
+	if err != nil {
+		var zero T
+		return zero, fieldError(err, tag{Name: name}, "", rv.Type(), val, false, opts)
+	}
+	return v, nil
+}
+
+// AsOr is As returning fallback when the variable is not set. A value that
+// cannot be parsed is still an error, returned along with fallback.
+func AsOr[T any](name string, fallback T, options ...Options) (T, error) {
+	v, err := As[T](name, options...)
+	if ok, err := found(err); !ok || err != nil {
+		return fallback, err
+	}
+	return v, nil
+}
+
+// MustAs is As panicking on any error, for use during program start up.
+func MustAs[T any](name string, options ...Options) T {
+	v, err := As[T](name, options...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -236,3 +236,288 @@ func TestHelperFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericHelpers(t *testing.T) {
+	src := env.Options{Source: env.MapSource{
+		"INT":      "8",
+		"LIST":     "1,2",
+		"MAP":      "one:1",
+		"URL":      "http://github.com/halorium/env",
+		"DURATION": "5s",
+		"INVALID":  "invalid",
+	}}
+
+	cases := []struct {
+		name string
+		run  runFunc
+		err  error
+		want interface{}
+	}{
+		{
+			name: "As int8",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[int8]("INT", src) },
+			err:  nil,
+			want: int8(8),
+		},
+		{
+			name: "As []int",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[[]int]("LIST", src) },
+			err:  nil,
+			want: []int{1, 2},
+		},
+		{
+			name: "As map[string]uint",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[map[string]uint]("MAP", src) },
+			err:  nil,
+			want: map[string]uint{"one": 1},
+		},
+		{
+			name: "As *time.Duration",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[*time.Duration]("DURATION", src) },
+			err:  nil,
+			want: ptrDuration(5 * time.Second),
+		},
+		{
+			name: "As Unmarshaler",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[CustomURL]("URL", src) },
+			err:  nil,
+			want: getCustomURL(),
+		},
+		{
+			name: "As not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[int]("MISSING", src) },
+			err:  fmt.Errorf("env: 'MISSING' not found"),
+			want: nil,
+		},
+		{
+			name: "As invalid",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[uint16]("INVALID", src) },
+			err:  fmt.Errorf("env: unable to parse ['INVALID'='invalid'] as uint16"),
+			want: nil,
+		},
+		{
+			name: "As unsupported type",
+			run:  func(t *testing.T) (interface{}, error) { return env.As[chan int]("INT", src) },
+			err:  fmt.Errorf("env: unsupported type 'chan int'"),
+			want: nil,
+		},
+		{
+			name: "AsOr set",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsOr("INT", 80, src) },
+			err:  nil,
+			want: 8,
+		},
+		{
+			name: "AsOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsOr("MISSING", []string{"a"}, src) },
+			err:  nil,
+			want: []string{"a"},
+		},
+		{
+			name: "AsOr invalid",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsOr("INVALID", 80, src) },
+			err:  fmt.Errorf("env: unable to parse ['INVALID'='invalid'] as int"),
+			want: nil,
+		},
+		{
+			name: "MustAs",
+			run:  func(t *testing.T) (interface{}, error) { return env.MustAs[int]("INT", src), nil },
+			err:  nil,
+			want: 8,
+		},
+		{
+			name: "MustAs panics",
+			run: func(t *testing.T) (v interface{}, err error) {
+				defer func() {
+					err = recover().(error)
+				}()
+				return env.MustAs[int]("MISSING", src), nil
+			},
+			err:  fmt.Errorf("env: 'MISSING' not found"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.run(t)
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+// found pairs a Lookup value with whether the variable was set
+type found struct {
+	Value interface{}
+	Found bool
+}
+
+func TestOrAndLookupHelpers(t *testing.T) {
+	src := env.Options{Source: env.MapSource{
+		"STRING":   "string",
+		"BOOL":     "true",
+		"INT":      "-8",
+		"UINT":     "8",
+		"FLOAT":    "1.5",
+		"DURATION": "5s",
+		"INVALID":  "invalid",
+	}}
+
+	cases := []struct {
+		name string
+		run  runFunc
+		err  error
+		want interface{}
+	}{
+		{
+			name: "AsStringOr set",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsStringOr("STRING", "default", src) },
+			err:  nil,
+			want: "string",
+		},
+		{
+			name: "AsStringOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsStringOr("MISSING", "default", src) },
+			err:  nil,
+			want: "default",
+		},
+		{
+			name: "AsBoolOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsBoolOr("MISSING", true, src) },
+			err:  nil,
+			want: true,
+		},
+		{
+			name: "AsBoolOr invalid",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsBoolOr("INVALID", true, src) },
+			err:  fmt.Errorf("env: unable to parse ['INVALID'='invalid'] as bool"),
+			want: nil,
+		},
+		{
+			name: "AsIntOr set",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsIntOr("INT", 64, 80, src) },
+			err:  nil,
+			want: int64(-8),
+		},
+		{
+			name: "AsIntOr out of range",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsIntOr("INT", 2, 1, src) },
+			err:  fmt.Errorf("env: unable to parse ['INT'='-8'] as int[2]"),
+			want: nil,
+		},
+		{
+			name: "AsUintOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsUintOr("MISSING", 64, 80, src) },
+			err:  nil,
+			want: uint64(80),
+		},
+		{
+			name: "AsFloatOr set",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsFloatOr("FLOAT", 64, 2.5, src) },
+			err:  nil,
+			want: 1.5,
+		},
+		{
+			name: "AsDurationOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsDurationOr("MISSING", time.Minute, src) },
+			err:  nil,
+			want: time.Minute,
+		},
+		{
+			name: "AsDurationOr invalid",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsDurationOr("INVALID", time.Minute, src) },
+			err:  fmt.Errorf(`env: unable to parse ['INVALID'='invalid'] as duration`),
+			want: nil,
+		},
+		{
+			name: "LookupString set",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupString("STRING", src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{"string", true},
+		},
+		{
+			name: "LookupBool not found",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupBool("MISSING", src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{false, false},
+		},
+		{
+			name: "LookupInt set",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupInt("INT", 8, src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{int64(-8), true},
+		},
+		{
+			name: "LookupUint invalid",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupUint("INVALID", 64, src)
+				if !ok {
+					t.Error("invalid variable reported as not found")
+				}
+				return found{v, ok}, err
+			},
+			err:  fmt.Errorf("env: unable to parse ['INVALID'='invalid'] as uint[64]"),
+			want: nil,
+		},
+		{
+			name: "LookupFloat not found",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupFloat("MISSING", 64, src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{float64(0), false},
+		},
+		{
+			name: "LookupDuration set",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupDuration("DURATION", src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{5 * time.Second, true},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.run(t)
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
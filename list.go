@@ -0,0 +1,278 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// listSeparators are the names sep= accepts besides a literal separator,
+// whitespace splits on runs of spaces, tabs and newlines
+var listSeparators = map[string]string{
+	"":           ",",
+	"space":      " ",
+	"tab":        "\t",
+	"newline":    "\n",
+	"whitespace": "",
+}
+
+// listSeparator returns the separator of the sep tag option, "" means runs
+// of whitespace
+func listSeparator(t tag) string {
+	if sep, ok := listSeparators[t.Sep]; ok {
+		return sep
+	}
+	return t.Sep
+}
+
+// mapSeparator returns the key/value separator of the kvsep tag option
+func mapSeparator(t tag) string {
+	if t.KVSep == "" {
+		return ":"
+	}
+	return t.KVSep
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// sepLen returns the length of the separator at v[i:], 0 if there is none
+func sepLen(v string, i int, sep string) int {
+	if sep == "" {
+		j := i
+		for j < len(v) && isSpace(v[j]) {
+			j++
+		}
+		return j - i
+	}
+	if sep == "\n" && strings.HasPrefix(v[i:], "\r\n") {
+		return 2
+	}
+	if strings.HasPrefix(v[i:], sep) {
+		return len(sep)
+	}
+	return 0
+}
+
+// listReader reads the elements of a list, or the keys and values of a
+// map, the way encoding/csv reads a record: elements may be quoted to
+// contain the separators, e.g. `"a,b",c`, and a backslash escapes a quote,
+// a backslash or a separator
+type listReader struct {
+	v     string
+	i     int
+	sep   string
+	kvsep string // "" for lists
+	trim  bool
+	what  string // "list: element" or "map: item", for errors
+}
+
+func newListReader(v string, t tag, kvsep string, what string) *listReader {
+	sep := listSeparator(t)
+	return &listReader{v: v, sep: sep, kvsep: kvsep, trim: t.Trim || sep == "", what: what}
+}
+
+// skipEmpty reports whether empty elements are dropped, so blank lines and
+// repeated spaces do not count
+func (r *listReader) skipEmpty() bool {
+	return r.sep == "" || r.sep == "\n"
+}
+
+func (r *listReader) done() bool {
+	return r.i == len(r.v)
+}
+
+// sepAt returns the length of the separator at v[i:]
+func (r *listReader) sepAt(i int) int {
+	return sepLen(r.v, i, r.sep)
+}
+
+// kvsepAt returns the length of the key/value separator at v[i:]
+func (r *listReader) kvsepAt(i int) int {
+	if r.kvsep == "" || !strings.HasPrefix(r.v[i:], r.kvsep) {
+		return 0
+	}
+	return len(r.kvsep)
+}
+
+// skipSpace skips spaces that are not a separator
+func (r *listReader) skipSpace() {
+	for r.i < len(r.v) && isSpace(r.v[r.i]) && r.sepAt(r.i) == 0 && r.kvsepAt(r.i) == 0 {
+		r.i++
+	}
+}
+
+// escaped reports whether the backslash at v[i] escapes what follows
+func (r *listReader) escaped(i int) bool {
+	return i+1 < len(r.v) && (r.v[i+1] == '"' || r.v[i+1] == '\\' || r.sepAt(i+1) != 0 || r.kvsepAt(i+1) != 0)
+}
+
+// element reads the nth element up to the separator, or up to the first
+// key/value separator as well when key is set
+func (r *listReader) element(n int, key bool) (string, bool, error) {
+	atEnd := func() bool {
+		return r.done() || r.sepAt(r.i) != 0 || key && r.kvsepAt(r.i) != 0
+	}
+	if r.trim {
+		r.skipSpace()
+	}
+
+	var b strings.Builder
+	quoted := !r.done() && r.v[r.i] == '"'
+	if !quoted {
+		for !atEnd() {
+			if r.v[r.i] == '\\' && r.escaped(r.i) {
+				r.i++
+			}
+			b.WriteByte(r.v[r.i])
+			r.i++
+		}
+		elem := b.String()
+		if r.trim {
+			elem = strings.TrimSpace(elem)
+		}
+		return elem, false, nil
+	}
+
+	r.i++
+	for {
+		if r.done() {
+			return "", true, fmt.Errorf("invalid %s %d has an unterminated quote", r.what, n)
+		}
+		c := r.v[r.i]
+		if c == '"' && r.i+1 < len(r.v) && r.v[r.i+1] == '"' {
+			// "" as in CSV
+			b.WriteByte('"')
+			r.i += 2
+			continue
+		}
+		if c == '"' {
+			r.i++
+			break
+		}
+		if c == '\\' && r.escaped(r.i) {
+			r.i++
+			c = r.v[r.i]
+		}
+		b.WriteByte(c)
+		r.i++
+	}
+	if r.trim {
+		r.skipSpace()
+	}
+	if !atEnd() {
+		return "", true, fmt.Errorf("invalid %s %d has text after its closing quote", r.what, n)
+	}
+	return b.String(), true, nil
+}
+
+// splitList splits a list on the separator of the sep tag option, see
+// listReader. The trim tag option trims spaces around elements.
+func splitList(v string, t tag) ([]string, error) {
+	r := newListReader(v, t, "", "list: element")
+
+	var elems []string
+	for n := 1; ; n++ {
+		elem, quoted, err := r.element(n, false)
+		if err != nil {
+			return nil, err
+		}
+		if elem != "" || quoted || !r.skipEmpty() {
+			elems = append(elems, elem)
+		}
+
+		if r.done() {
+			return elems, nil
+		}
+		r.i += r.sepAt(r.i)
+	}
+}
+
+// splitMap splits a map into its keys and values, items are separated as
+// by splitList and split on the first key/value separator, so values may
+// contain it, e.g. "api:https://api.local:8443"
+func splitMap(v string, t tag) ([][2]string, error) {
+	kvsep := mapSeparator(t)
+	r := newListReader(v, t, kvsep, "map: item")
+
+	var items [][2]string
+	for n := 1; ; n++ {
+		key, quoted, err := r.element(n, true)
+		if err != nil {
+			return nil, err
+		}
+		if r.kvsepAt(r.i) != 0 {
+			r.i += r.kvsepAt(r.i)
+			val, _, err := r.element(n, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, [2]string{key, val})
+		} else if key != "" || quoted || !r.skipEmpty() {
+			return nil, fmt.Errorf("invalid map: item %d has no %q between key and value", n, kvsep)
+		}
+
+		if r.done() {
+			return items, nil
+		}
+		r.i += r.sepAt(r.i)
+	}
+}
+
+// joinList is the inverse of splitList, elements that would not split back
+// to themselves are quoted
+func joinList(elems []string, t tag) string {
+	sep := listSeparator(t)
+	quoteEmpty := len(elems) == 1 || sep == "" || sep == "\n"
+
+	quoted := make([]string, len(elems))
+	for i, e := range elems {
+		quoted[i] = quoteElement(e, needsQuote(e, sep, quoteEmpty))
+	}
+	return strings.Join(quoted, joinSeparator(sep))
+}
+
+// joinMap is the inverse of splitMap, items are sorted as maps have no
+// order
+func joinMap(items [][2]string, t tag) string {
+	sep, kvsep := listSeparator(t), mapSeparator(t)
+
+	pairs := make([]string, len(items))
+	for i, kv := range items {
+		k, v := kv[0], kv[1]
+		pairs[i] = quoteElement(k, needsQuote(k, sep, false) || strings.Contains(k, kvsep)) +
+			kvsep + quoteElement(v, needsQuote(v, sep, false))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, joinSeparator(sep))
+}
+
+func joinSeparator(sep string) string {
+	if sep == "" {
+		return " "
+	}
+	return sep
+}
+
+func quoteElement(e string, quote bool) string {
+	if !quote {
+		return e
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(e) + `"`
+}
+
+func needsQuote(e string, sep string, quoteEmpty bool) bool {
+	if e == "" {
+		return quoteEmpty
+	}
+	if isSpace(e[0]) || isSpace(e[len(e)-1]) || strings.ContainsAny(e, `"\`) {
+		return true
+	}
+	for i := 0; i < len(e); i++ {
+		if sepLen(e, i, sep) != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package env_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+func TestListParsing(t *testing.T) {
+	cases := []struct {
+		name  string
+		tag   string
+		value string
+		err   error
+		want  []string
+	}{
+		{name: "plain", tag: "LIST", value: "a,b,c", err: nil, want: []string{"a", "b", "c"}},
+		{name: "spaces kept", tag: "LIST", value: "a, b", err: nil, want: []string{"a", " b"}},
+		{name: "empty elements", tag: "LIST", value: "a,,b,", err: nil, want: []string{"a", "", "b", ""}},
+		{name: "quoted separator", tag: "LIST", value: `"a,b",c`, err: nil, want: []string{"a,b", "c"}},
+		{name: "quoted empty", tag: "LIST", value: `""`, err: nil, want: []string{""}},
+		{name: "doubled quote", tag: "LIST", value: `"say ""hi""",x`, err: nil, want: []string{`say "hi"`, "x"}},
+		{name: "escaped quote", tag: "LIST", value: `"say \"hi\"",x`, err: nil, want: []string{`say "hi"`, "x"}},
+		{name: "escaped separator", tag: "LIST", value: `a\,b,c`, err: nil, want: []string{"a,b", "c"}},
+		{name: "escaped backslash", tag: "LIST", value: `a\\,b`, err: nil, want: []string{`a\`, "b"}},
+		{name: "other backslashes kept", tag: "LIST", value: `C:\dir,\n`, err: nil, want: []string{`C:\dir`, `\n`}},
+		{name: "inner quote kept", tag: "LIST", value: `5" floppy,b`, err: nil, want: []string{`5" floppy`, "b"}},
+		{name: "trim", tag: "LIST,trim", value: " a , b ,c ", err: nil, want: []string{"a", "b", "c"}},
+		{name: "trim keeps quoted spaces", tag: "LIST,trim", value: ` " a " , b`, err: nil, want: []string{" a ", "b"}},
+		{name: "semicolon", tag: "LIST,sep=;", value: "a,b;c", err: nil, want: []string{"a,b", "c"}},
+		{name: "pipe", tag: "LIST,sep=|", value: `a|"b|c"|d\|e`, err: nil, want: []string{"a", "b|c", "d|e"}},
+		{name: "multi-character", tag: "LIST,sep=::", value: "a:b::c", err: nil, want: []string{"a:b", "c"}},
+		{name: "space", tag: "LIST,sep=space", value: "a b  c", err: nil, want: []string{"a", "b", "", "c"}},
+		{name: "tab", tag: "LIST,sep=tab,trim", value: "a \t b\tc", err: nil, want: []string{"a", "b", "c"}},
+		{name: "whitespace", tag: "LIST,sep=whitespace", value: "  a\tb \n c\n", err: nil, want: []string{"a", "b", "c"}},
+		{name: "whitespace quoted", tag: "LIST,sep=whitespace", value: `"a b" c\ d ""`, err: nil, want: []string{"a b", "c d", ""}},
+		{name: "newline", tag: "LIST,sep=newline", value: "a b\r\nc,d\n\ne\n", err: nil, want: []string{"a b", "c,d", "e"}},
+		{name: "default", tag: "LIST,sep=;,trim,default=a; b", value: "", err: nil, want: []string{"a", "b"}},
+		{
+			name:  "unterminated quote",
+			tag:   "LIST",
+			value: `a,"b,c`,
+			err:   fmt.Errorf(`env: unable to parse ['LIST'='a,"b,c'] as []string for field 'List': invalid list: element 2 has an unterminated quote`),
+		},
+		{
+			name:  "text after quote",
+			tag:   "LIST",
+			value: `"a"b,c`,
+			err:   fmt.Errorf(`env: unable to parse ['LIST'='"a"b,c'] as []string for field 'List': invalid list: element 1 has text after its closing quote`),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// an empty value leaves LIST unset for the default case
+			src := env.MapSource{}
+			if c.value != "" {
+				src["LIST"] = c.value
+			}
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "List",
+				Type: reflect.TypeOf([]string(nil)),
+				Tag:  reflect.StructTag(fmt.Sprintf("env:%q", c.tag)),
+			}}))
+			err := env.Unmarshal(obj.Interface(), env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if got := obj.Elem().Field(0).Interface(); !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestListElementTypes(t *testing.T) {
+	src := env.MapSource{"PORTS": "80; 443 ;8080", "WEIGHTS": "0.5|1.5"}
+	var cfg struct {
+		Ports   []int     `env:"PORTS,sep=;,trim"`
+		Weights []float64 `env:"WEIGHTS,sep=|"`
+	}
+	if err := env.Unmarshal(&cfg, env.Options{Source: src}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual([]int{80, 443, 8080}, cfg.Ports) || !reflect.DeepEqual([]float64{0.5, 1.5}, cfg.Weights) {
+		t.Errorf("\ngot:'%#v'\n", cfg)
+	}
+}
+
+func TestMarshalList(t *testing.T) {
+	type config struct {
+		Plain      []string `env:"PLAIN"`
+		Quoted     []string `env:"QUOTED"`
+		Empty      []string `env:"EMPTY"`
+		Semicolon  []string `env:"SEMICOLON,sep=;"`
+		Whitespace []string `env:"WHITESPACE,sep=whitespace"`
+		Lines      []string `env:"LINES,sep=newline"`
+		Trimmed    []string `env:"TRIMMED,trim"`
+	}
+	cfg := config{
+		Plain:      []string{"a", "b", ""},
+		Quoted:     []string{"a,b", `say "hi"`, `C:\dir`, " padded "},
+		Empty:      []string{""},
+		Semicolon:  []string{"a,b", "c;d"},
+		Whitespace: []string{"a b", "c", ""},
+		Lines:      []string{"one line", "two\nlines"},
+		Trimmed:    []string{" a", "b"},
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Vars{
+		{Name: "PLAIN", Value: "a,b,"},
+		{Name: "QUOTED", Value: `"a,b","say \"hi\"","C:\\dir"," padded "`},
+		{Name: "EMPTY", Value: `""`},
+		{Name: "SEMICOLON", Value: `a,b;"c;d"`},
+		{Name: "WHITESPACE", Value: `"a b" c ""`},
+		{Name: "LINES", Value: "one line\n\"two\nlines\""},
+		{Name: "TRIMMED", Value: `" a",b`},
+	}
+	if !reflect.DeepEqual(want, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, vars)
+	}
+
+	var got config
+	if err := env.Unmarshal(&got, env.Options{Source: vars.Map()}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", cfg, got)
+	}
+}
+
+func TestMapParsing(t *testing.T) {
+	cases := []struct {
+		name  string
+		tag   string
+		value string
+		err   error
+		want  map[string]string
+	}{
+		{name: "plain", tag: "MAP", value: "a:1,b:2", err: nil, want: map[string]string{"a": "1", "b": "2"}},
+		{name: "colons in values", tag: "MAP", value: "api:https://api.local:8443,db:x", err: nil, want: map[string]string{"api": "https://api.local:8443", "db": "x"}},
+		{name: "empty key and value", tag: "MAP", value: ":a,b:", err: nil, want: map[string]string{"": "a", "b": ""}},
+		{name: "quoted key", tag: "MAP", value: `"a:b":c`, err: nil, want: map[string]string{"a:b": "c"}},
+		{name: "quoted value", tag: "MAP", value: `a:"1,2",b:"say ""hi"""`, err: nil, want: map[string]string{"a": "1,2", "b": `say "hi"`}},
+		{name: "escaped separators", tag: "MAP", value: `a\:b:c\,d`, err: nil, want: map[string]string{"a:b": "c,d"}},
+		{name: "trim", tag: "MAP,trim", value: " a : 1 , b:2 ", err: nil, want: map[string]string{"a": "1", "b": "2"}},
+		{name: "kvsep and sep", tag: "MAP,kvsep==,sep=;", value: "team=core;tier=1;url=http://x?a=b", err: nil, want: map[string]string{"team": "core", "tier": "1", "url": "http://x?a=b"}},
+		{name: "newline", tag: "MAP,kvsep==,sep=newline", value: "a=1\n\nb=2\n", err: nil, want: map[string]string{"a": "1", "b": "2"}},
+		{name: "whitespace", tag: "MAP,sep=whitespace", value: " a:1  b:2\t", err: nil, want: map[string]string{"a": "1", "b": "2"}},
+		{name: "allowdups", tag: "MAP,allowdups", value: "a:1,a:2", err: nil, want: map[string]string{"a": "2"}},
+		{
+			name:  "duplicate key",
+			tag:   "MAP",
+			value: "a:1,b:2,a:3",
+			err:   fmt.Errorf(`env: unable to parse ['MAP'='a:1,b:2,a:3'] as map[string]string for field 'Map': invalid map: item 3 repeats an earlier key`),
+		},
+		{
+			name:  "missing kvsep",
+			tag:   "MAP",
+			value: "a:1,b",
+			err:   fmt.Errorf(`env: unable to parse ['MAP'='a:1,b'] as map[string]string for field 'Map': invalid map: item 2 has no ":" between key and value`),
+		},
+		{
+			name:  "trailing separator",
+			tag:   "MAP,kvsep==",
+			value: "a=1,",
+			err:   fmt.Errorf(`env: unable to parse ['MAP'='a=1,'] as map[string]string for field 'Map': invalid map: item 2 has no "=" between key and value`),
+		},
+		{
+			name:  "unterminated quote",
+			tag:   "MAP",
+			value: `a:"1,b:2`,
+			err:   fmt.Errorf(`env: unable to parse ['MAP'='a:"1,b:2'] as map[string]string for field 'Map': invalid map: item 1 has an unterminated quote`),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := env.MapSource{"MAP": c.value}
+			obj := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "Map",
+				Type: reflect.TypeOf(map[string]string(nil)),
+				Tag:  reflect.StructTag(fmt.Sprintf("env:%q", c.tag)),
+			}}))
+			err := env.Unmarshal(obj.Interface(), env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if got := obj.Elem().Field(0).Interface(); !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMapDuplicateKeys(t *testing.T) {
+	// keys are compared after parsing
+	src := env.MapSource{"PORTS": "80:http,+80:alt"}
+	var cfg struct {
+		Ports map[int]string `env:"PORTS"`
+	}
+	err := env.Unmarshal(&cfg, env.Options{Source: src})
+	want := "env: unable to parse ['PORTS'='80:http,+80:alt'] as map[int]string for field 'Ports': invalid map: item 2 repeats an earlier key"
+	if err == nil || err.Error() != want {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+	}
+}
+
+func TestMarshalMap(t *testing.T) {
+	type config struct {
+		Endpoints map[string]string `env:"ENDPOINTS"`
+		Labels    map[string]string `env:"LABELS,kvsep==,sep=;"`
+		Odd       map[string]string `env:"ODD"`
+		Limits    map[string]int    `env:"LIMITS,sep=whitespace"`
+	}
+	cfg := config{
+		Endpoints: map[string]string{"api": "https://api.local:8443", "db": "pg"},
+		Labels:    map[string]string{"team": "core", "tier": "1", "a=b": "c;d"},
+		Odd:       map[string]string{"a:b": "", "": " x", `q"`: "1,2"},
+		Limits:    map[string]int{"a": 1, "b": 2},
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Vars{
+		{Name: "ENDPOINTS", Value: "api:https://api.local:8443,db:pg"},
+		{Name: "LABELS", Value: `"a=b"="c;d";team=core;tier=1`},
+		{Name: "ODD", Value: `"a:b":,"q\"":"1,2",:" x"`},
+		{Name: "LIMITS", Value: "a:1 b:2"},
+	}
+	if !reflect.DeepEqual(want, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, vars)
+	}
+
+	var got config
+	if err := env.Unmarshal(&got, env.Options{Source: vars.Map()}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", cfg, got)
+	}
+}
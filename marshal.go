@@ -0,0 +1,257 @@
+package env
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type Marshaler interface {
+	MarshalENV() (string, error)
+}
+
+var (
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+func asMarshaler(rv reflect.Value) Marshaler {
+	m, _ := ptrInterface(rv).(Marshaler)
+	return m
+}
+
+func asTextMarshaler(rv reflect.Value) encoding.TextMarshaler {
+	m, _ := ptrInterface(rv).(encoding.TextMarshaler)
+	return m
+}
+
+func asBinaryMarshaler(rv reflect.Value) encoding.BinaryMarshaler {
+	m, _ := ptrInterface(rv).(encoding.BinaryMarshaler)
+	return m
+}
+
+// ptrInterface is addrInterface without instantiating nil pointers
+func ptrInterface(rv reflect.Value) interface{} {
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() {
+		rv = rv.Addr()
+	}
+	if !rv.CanInterface() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// Var is a variable produced by Marshal.
+type Var struct {
+	Name  string
+	Value string
+}
+
+// Vars is an ordered list of variables.
+type Vars []Var
+
+// Map returns the variables as a MapSource, e.g. to Unmarshal them again.
+func (vs Vars) Map() MapSource {
+	m := MapSource{}
+	for _, v := range vs {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+// Environ returns the variables as "NAME=value" strings, the format of
+// os.Environ and exec.Cmd.Env.
+func (vs Vars) Environ() []string {
+	environ := make([]string, len(vs))
+	for i, v := range vs {
+		environ[i] = v.Name + "=" + v.Value
+	}
+	return environ
+}
+
+// Marshal is the inverse of Unmarshal, it returns the tagged fields of obj
+// as variables in field order. Values are formatted the way Unmarshal
+// parses them and nil pointers, slices and maps are left out.
+func Marshal(obj interface{}, options ...Options) (Vars, error) {
+	// get default options and merge in any overrides
+	opts := getOptions(options...)
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, ErrInvalidType
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidType
+	}
+
+	// work on an addressable copy so pointer receiver MarshalENV methods
+	// can be called and obj is never modified
+	cp := reflect.New(rv.Type()).Elem()
+	cp.Set(rv)
+
+	vars := Vars{}
+	err := walkStruct(cp, "", opts, false, func(f field) error {
+		if isNil(f.Value) {
+			return nil
+		}
+		val, err := formatValue(f.Value, f.Tag)
+		if err != nil {
+			var ute *UnsupportedTypeError
+			if errors.As(err, &ute) {
+				return &UnsupportedTypeError{Field: f.Path, Type: ute.Type}
+			}
+			return err
+		}
+		vars = append(vars, Var{Name: f.Tag.Name, Value: val})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func formatValue(rv reflect.Value, t tag) (string, error) {
+	// a nil element of a list or map, before any method gets a nil receiver
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "", nil
+	}
+
+	// check for custom MarshalENV function
+	if m := asMarshaler(rv); m != nil {
+		return m.MarshalENV()
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if formatter, ok := typeFormatters[rv.Type()]; ok {
+		return formatter(rv, t)
+	}
+
+	// fall back to the encoding interfaces
+	if t.Base64 {
+		if m := asBinaryMarshaler(rv); m != nil {
+			b, err := m.MarshalBinary()
+			return base64.StdEncoding.EncodeToString(b), err
+		}
+	}
+	if m := asTextMarshaler(rv); m != nil {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	formatter, ok := formatters[rv.Kind()]
+	if !ok {
+		return "", &UnsupportedTypeError{Type: rv.Type()}
+	}
+	return formatter(rv, t)
+}
+
+// Required due to init cycle
+func init() {
+	formatters[reflect.Slice] = func(f reflect.Value, t tag) (string, error) {
+		if f.Type().Elem().Kind() == reflect.Uint8 {
+			if t.Base64 {
+				return base64.StdEncoding.EncodeToString(f.Bytes()), nil
+			}
+			return string(f.Bytes()), nil
+		}
+		vals := make([]string, f.Len())
+		for i := range vals {
+			v, err := formatValue(f.Index(i), t)
+			if err != nil {
+				return "", err
+			}
+			vals[i] = v
+		}
+		return joinList(vals, t), nil
+	}
+
+	formatters[reflect.Map] = func(f reflect.Value, t tag) (string, error) {
+		items := make([][2]string, 0, f.Len())
+		iter := f.MapRange()
+		for iter.Next() {
+			k, err := formatValue(iter.Key(), t)
+			if err != nil {
+				return "", err
+			}
+			v, err := formatValue(iter.Value(), t)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, [2]string{k, v})
+		}
+		return joinMap(items, t), nil
+	}
+}
+
+type formatter func(f reflect.Value, t tag) (string, error)
+
+// typeFormatters mirrors typeParsers
+var typeFormatters = map[reflect.Type]formatter{
+	durationType: func(f reflect.Value, t tag) (string, error) {
+		return time.Duration(f.Int()).String(), nil
+	},
+	timeType: func(f reflect.Value, t tag) (string, error) {
+		return formatTime(f.Interface().(time.Time), t.Layout), nil
+	},
+}
+
+var formatters = map[reflect.Kind]formatter{
+	reflect.String: func(f reflect.Value, t tag) (string, error) {
+		return f.String(), nil
+	},
+	reflect.Bool: func(f reflect.Value, t tag) (string, error) {
+		return strconv.FormatBool(f.Bool()), nil
+	},
+	reflect.Int:    formatInt,
+	reflect.Int8:   formatInt,
+	reflect.Int16:  formatInt,
+	reflect.Int32:  formatInt,
+	reflect.Int64:  formatInt,
+	reflect.Uint:   formatUint,
+	reflect.Uint8:  formatUint,
+	reflect.Uint16: formatUint,
+	reflect.Uint32: formatUint,
+	reflect.Uint64: formatUint,
+	reflect.Float32: func(f reflect.Value, t tag) (string, error) {
+		return strconv.FormatFloat(f.Float(), 'g', -1, 32), nil
+	},
+	reflect.Float64: func(f reflect.Value, t tag) (string, error) {
+		return strconv.FormatFloat(f.Float(), 'g', -1, 64), nil
+	},
+}
+
+func formatInt(f reflect.Value, t tag) (string, error) {
+	if t.Size {
+		if n := f.Int(); n < 0 {
+			return "-" + formatSize(uint64(-n)), nil
+		}
+		return formatSize(uint64(f.Int())), nil
+	}
+	return strconv.FormatInt(f.Int(), 10), nil
+}
+
+func formatUint(f reflect.Value, t tag) (string, error) {
+	if t.Size {
+		return formatSize(f.Uint()), nil
+	}
+	return strconv.FormatUint(f.Uint(), 10), nil
+}
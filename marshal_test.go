@@ -0,0 +1,178 @@
+package env_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+type marshalConfig struct {
+	String   string         `env:"STRING"`
+	Bool     bool           `env:"BOOL"`
+	Int      int            `env:"INT"`
+	Uint     uint16         `env:"UINT"`
+	Float    float32        `env:"FLOAT"`
+	Duration time.Duration  `env:"DURATION"`
+	List     []int          `env:"LIST"`
+	Bytes    []byte         `env:"BYTES"`
+	Map      map[string]int `env:"MAP"`
+	Ptr      *string        `env:"PTR"`
+	URL      *MarshalURL    `env:"URL"`
+	Ignored  string         `env:"-"`
+	Nested   struct {
+		String string `env:"NESTED"`
+	}
+	NestedPtr *NestedStruct
+}
+
+type MarshalURL url.URL
+
+func (u *MarshalURL) UnmarshalENV(v string) error {
+	p, err := url.Parse(v)
+	if err != nil {
+		return err
+	}
+	*u = MarshalURL(*p)
+	return nil
+}
+
+func (u *MarshalURL) MarshalENV() (string, error) {
+	return (*url.URL)(u).String(), nil
+}
+
+func TestMarshal(t *testing.T) {
+	full := marshalConfig{
+		String:   "string_val",
+		Bool:     true,
+		Int:      -1,
+		Uint:     2,
+		Float:    2.3,
+		Duration: 90 * time.Second,
+		List:     []int{1, 2},
+		Bytes:    []byte("some data"),
+		Map:      map[string]int{"two": 2, "one": 1},
+		Ptr:      ptrStr("ptr_val"),
+		URL:      &MarshalURL{Scheme: "http", Host: "github.com", Path: "/halorium/env"},
+		Ignored:  "ignored",
+	}
+	full.Nested.String = "nested_val"
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want env.Vars
+	}{
+		{
+			name: "object is nil",
+			obj:  nil,
+			err:  env.ErrInvalidType,
+			want: nil,
+		},
+		{
+			name: "object is non-struct",
+			obj:  ptrStr(""),
+			err:  env.ErrInvalidType,
+			want: nil,
+		},
+		{
+			name: "every supported kind",
+			obj:  &full,
+			err:  nil,
+			want: env.Vars{
+				{Name: "STRING", Value: "string_val"},
+				{Name: "BOOL", Value: "true"},
+				{Name: "INT", Value: "-1"},
+				{Name: "UINT", Value: "2"},
+				{Name: "FLOAT", Value: "2.3"},
+				{Name: "DURATION", Value: "1m30s"},
+				{Name: "LIST", Value: "1,2"},
+				{Name: "BYTES", Value: "some data"},
+				{Name: "MAP", Value: "one:1,two:2"},
+				{Name: "PTR", Value: "ptr_val"},
+				{Name: "URL", Value: "http://github.com/halorium/env"},
+				{Name: "NESTED", Value: "nested_val"},
+			},
+		},
+		{
+			name: "nil pointers slices and maps are left out",
+			obj:  marshalConfig{},
+			err:  nil,
+			want: env.Vars{
+				{Name: "STRING", Value: ""},
+				{Name: "BOOL", Value: "false"},
+				{Name: "INT", Value: "0"},
+				{Name: "UINT", Value: "0"},
+				{Name: "FLOAT", Value: "0"},
+				{Name: "DURATION", Value: "0s"},
+				{Name: "NESTED", Value: ""},
+			},
+		},
+		{
+			name: "nil elements of lists and maps",
+			obj: struct {
+				URLs   []*MarshalURL          `env:"URLS"`
+				ByName map[string]*MarshalURL `env:"BY_NAME"`
+			}{
+				URLs:   []*MarshalURL{{Scheme: "http", Host: "a"}, nil},
+				ByName: map[string]*MarshalURL{"a": {Scheme: "http", Host: "a"}, "b": nil},
+			},
+			err: nil,
+			want: env.Vars{
+				{Name: "URLS", Value: "http://a,"},
+				{Name: "BY_NAME", Value: "a:http://a,b:"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := env.Marshal(c.obj)
+			if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+			}
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		vars, err := env.Marshal(full)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got marshalConfig
+		err = env.Unmarshal(&got, env.Options{Source: vars.Map()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := full
+		want.Ignored = ""
+		want.NestedPtr = &NestedStruct{String: "nested_val"}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+
+	t.Run("environ", func(t *testing.T) {
+		vars := env.Vars{{Name: "ONE", Value: "1"}, {Name: "TWO", Value: "a=b"}}
+		want := []string{"ONE=1", "TWO=a=b"}
+		if got := vars.Environ(); !reflect.DeepEqual(want, got) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := env.Marshal(struct {
+			Chan chan int `env:"CHAN"`
+		}{Chan: make(chan int)})
+		if !errors.Is(err, env.ErrUnsupportedType) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", env.ErrUnsupportedType, err)
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const numberFormats = "want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T"
+
+var multipliers = map[byte]int{'k': 3, 'K': 3, 'M': 6, 'G': 9, 'T': 12}
+
+// parseInt parses an int of bitSize bits the way the tag and options ask
+func parseInt(v string, bitSize int, t tag, opts Options) (int64, error) {
+	switch {
+	case t.Size:
+		return parseSizeInt(v, bitSize)
+	case t.SI || opts.SINumbers:
+		return parseSIInt(v, bitSize)
+	}
+	return strconv.ParseInt(v, 0, bitSize)
+}
+
+// parseUint parses a uint of bitSize bits the way the tag and options ask
+func parseUint(v string, bitSize int, t tag, opts Options) (uint64, error) {
+	switch {
+	case t.Size:
+		return parseSizeUint(v, bitSize)
+	case t.SI || opts.SINumbers:
+		return parseSIUint(v, bitSize)
+	}
+	return strconv.ParseUint(v, 0, bitSize)
+}
+
+// parseFloat parses a float of bitSize bits the way the tag and options ask
+func parseFloat(v string, bitSize int, t tag, opts Options) (float64, error) {
+	if t.SI || opts.SINumbers {
+		return parseSIFloat(v, bitSize)
+	}
+	return strconv.ParseFloat(v, bitSize)
+}
+
+// parseSIInt accepts what strconv.ParseInt does and the forms of parseSI
+// that are whole numbers
+func parseSIInt(v string, bitSize int) (int64, error) {
+	n, err := strconv.ParseInt(v, 0, bitSize)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return n, err
+	}
+	i, err := parseSIWhole(v)
+	if err != nil {
+		return 0, err
+	}
+	return intInRange(i, bitSize)
+}
+
+// parseSIUint accepts what strconv.ParseUint does and the forms of parseSI
+// that are whole numbers
+func parseSIUint(v string, bitSize int) (uint64, error) {
+	n, err := strconv.ParseUint(v, 0, bitSize)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return n, err
+	}
+	i, err := parseSIWhole(v)
+	if err != nil {
+		return 0, err
+	}
+	return uintInRange(i, bitSize)
+}
+
+// parseSIFloat accepts what strconv.ParseFloat does and a multiplier
+func parseSIFloat(v string, bitSize int) (float64, error) {
+	f, err := strconv.ParseFloat(v, bitSize)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return f, err
+	}
+	r, err := parseSI(v)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize == 32 {
+		f32, _ := r.Float32()
+		f = float64(f32)
+	} else {
+		f, _ = r.Float64()
+	}
+	if math.IsInf(f, 0) {
+		return f, strconv.ErrRange
+	}
+	return f, nil
+}
+
+func parseSIWhole(v string) (*big.Int, error) {
+	r, err := parseSI(v)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("invalid number %q: not a whole number", v)
+	}
+	return r.Num(), nil
+}
+
+// parseSI parses a decimal number exactly, digits may be separated by
+// underscores and it may have a fraction, an exponent or a multiplier,
+// e.g. "-1_500", "1.5e3" or "1.5k"
+func parseSI(v string) (*big.Rat, error) {
+	s := v
+	exp := 0
+	if s != "" {
+		if e, ok := multipliers[s[len(s)-1]]; ok {
+			s, exp = s[:len(s)-1], e
+		}
+	}
+
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exp != 0 {
+			return nil, fmt.Errorf("invalid number %q: an exponent and a multiplier (%s)", v, numberFormats)
+		}
+		mantissa, exponent = s[:i], s[i+1:]
+		if exponent == "" {
+			return nil, fmt.Errorf("invalid number %q (%s)", v, numberFormats)
+		}
+	}
+
+	digits, ok := siDigits(mantissa)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q (%s)", v, numberFormats)
+	}
+	if exponent != "" {
+		e, err := strconv.Atoi(exponent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q (%s)", v, numberFormats)
+		}
+		// far beyond any int or float, keep big.Rat from allocating
+		if e > 1000 || e < -1000 {
+			return nil, strconv.ErrRange
+		}
+		exp = e
+	}
+
+	r, _ := new(big.Rat).SetString(digits)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil))
+	if exp < 0 {
+		return r.Quo(r, scale), nil
+	}
+	return r.Mul(r, scale), nil
+}
+
+// siDigits checks a signed decimal with an optional fraction and removes
+// the underscores, which must each sit between two digits
+func siDigits(s string) (string, bool) {
+	sign := ""
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+	var b strings.Builder
+	dot, digits := false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9':
+			b.WriteByte(c)
+			digits++
+		case c == '_':
+			if i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1]) {
+				return "", false
+			}
+		case c == '.' && !dot:
+			b.WriteByte(c)
+			dot = true
+		default:
+			return "", false
+		}
+	}
+	return sign + b.String(), digits != 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// intInRange returns n if it fits in an int of bitSize bits, 0 is the
+// size of int as in strconv
+func intInRange(n *big.Int, bitSize int) (int64, error) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	if !n.IsInt64() || n.Int64() < math.MinInt64>>(64-bitSize) || n.Int64() > math.MaxInt64>>(64-bitSize) {
+		return 0, strconv.ErrRange
+	}
+	return n.Int64(), nil
+}
+
+// uintInRange returns n if it fits in a uint of bitSize bits, 0 is the
+// size of uint as in strconv
+func uintInRange(n *big.Int, bitSize int) (uint64, error) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	if !n.IsUint64() || n.Uint64() > math.MaxUint64>>(64-bitSize) {
+		return 0, strconv.ErrRange
+	}
+	return n.Uint64(), nil
+}
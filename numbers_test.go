@@ -0,0 +1,326 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+func TestSINumbers(t *testing.T) {
+	src := env.MapSource{
+		"WORKERS": "10k",
+		"RATE":    "1.5M",
+		"POOL":    "1_500",
+		"EXP":     "1e3",
+		"NEG":     "-2k",
+		"HEX":     "0x1F",
+		"RATIO":   "2.5k",
+		"TINY":    "1.5e-3",
+		"LIMITS":  "1k,2M",
+		"HALF":    "1.5",
+		"BOTH":    "1e3k",
+		"MILLI":   "5m",
+		"UNDER":   "1__000",
+		"HUGE":    "1e1001",
+		"NOEXP":   "1e",
+		"NOEXPF":  "5E",
+	}
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want interface{}
+	}{
+		{
+			name: "int, uint and float fields",
+			obj: &struct {
+				Workers int     `env:"WORKERS,si"`
+				Rate    *uint64 `env:"RATE,si"`
+				Pool    int16   `env:"POOL,si"`
+				Exp     uint    `env:"EXP,si"`
+				Neg     int32   `env:"NEG,si"`
+				Hex     uint8   `env:"HEX,si"`
+				Ratio   float64 `env:"RATIO,si"`
+				Tiny    float32 `env:"TINY,si"`
+				Limits  []int   `env:"LIMITS,si"`
+				Burst   uint32  `env:"BURST,si,default=1G"`
+			}{},
+			err: nil,
+			want: &struct {
+				Workers int     `env:"WORKERS,si"`
+				Rate    *uint64 `env:"RATE,si"`
+				Pool    int16   `env:"POOL,si"`
+				Exp     uint    `env:"EXP,si"`
+				Neg     int32   `env:"NEG,si"`
+				Hex     uint8   `env:"HEX,si"`
+				Ratio   float64 `env:"RATIO,si"`
+				Tiny    float32 `env:"TINY,si"`
+				Limits  []int   `env:"LIMITS,si"`
+				Burst   uint32  `env:"BURST,si,default=1G"`
+			}{Workers: 10e3, Rate: ptrUint64(1.5e6), Pool: 1500, Exp: 1000, Neg: -2000, Hex: 31, Ratio: 2500, Tiny: 1.5e-3, Limits: []int{1e3, 2e6}, Burst: 1e9},
+		},
+		{
+			name: "not a whole number",
+			obj: &struct {
+				Half int `env:"HALF,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['HALF'='1.5'] as int for field 'Half': invalid number "1.5": not a whole number`),
+			want: nil,
+		},
+		{
+			name: "exponent and multiplier",
+			obj: &struct {
+				Both int `env:"BOTH,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['BOTH'='1e3k'] as int for field 'Both': invalid number "1e3k": an exponent and a multiplier (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`),
+			want: nil,
+		},
+		{
+			name: "unknown multiplier",
+			obj: &struct {
+				Milli float64 `env:"MILLI,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['MILLI'='5m'] as float64 for field 'Milli': invalid number "5m" (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`),
+			want: nil,
+		},
+		{
+			name: "misplaced underscores",
+			obj: &struct {
+				Under int `env:"UNDER,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['UNDER'='1__000'] as int for field 'Under': invalid number "1__000" (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`),
+			want: nil,
+		},
+		{
+			name: "dangling exponent",
+			obj: &struct {
+				NoExp int `env:"NOEXP,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['NOEXP'='1e'] as int for field 'NoExp': invalid number "1e" (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`),
+			want: nil,
+		},
+		{
+			name: "dangling float exponent",
+			obj: &struct {
+				NoExp float64 `env:"NOEXPF,si"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['NOEXPF'='5E'] as float64 for field 'NoExp': invalid number "5E" (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`),
+			want: nil,
+		},
+		{
+			name: "overflows the bit size",
+			obj: &struct {
+				Workers int8 `env:"WORKERS,si"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['WORKERS'='10k'] as int8 for field 'Workers': value out of range"),
+			want: nil,
+		},
+		{
+			name: "negative uint",
+			obj: &struct {
+				Neg uint `env:"NEG,si"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['NEG'='-2k'] as uint for field 'Neg': value out of range"),
+			want: nil,
+		},
+		{
+			name: "huge exponent",
+			obj: &struct {
+				Huge float64 `env:"HUGE,si"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['HUGE'='1e1001'] as float64 for field 'Huge': value out of range"),
+			want: nil,
+		},
+		{
+			name: "without the option",
+			obj: &struct {
+				Workers int `env:"WORKERS"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['WORKERS'='10k'] as int for field 'Workers': invalid syntax"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+}
+
+func TestSINumbersOption(t *testing.T) {
+	src := env.MapSource{"WORKERS": "10k", "RATE": "1_500", "RATIO": "2.5k"}
+
+	t.Run("off by default", func(t *testing.T) {
+		_, err := env.AsInt("WORKERS", 64, env.Options{Source: src})
+		want := `env: unable to parse ['WORKERS'='10k'] as int[64]`
+		if err == nil || err.Error() != want || !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	opts := env.Options{Source: src, SINumbers: true}
+
+	t.Run("AsInt", func(t *testing.T) {
+		got, err := env.AsInt("WORKERS", 64, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 10e3 {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", 10e3, got)
+		}
+	})
+
+	t.Run("AsInt native size", func(t *testing.T) {
+		got, err := env.AsInt("WORKERS", 0, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 10e3 {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", 10e3, got)
+		}
+	})
+
+	t.Run("AsUint native size", func(t *testing.T) {
+		got, err := env.AsUint("WORKERS", 0, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 10e3 {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", 10e3, got)
+		}
+	})
+
+	t.Run("AsFloat dangling exponent", func(t *testing.T) {
+		_, err := env.AsFloat("EXP", 64, env.Options{Source: env.MapSource{"EXP": "5E"}, SINumbers: true})
+		// the accepted formats are shown once the option is on
+		want := `env: unable to parse ['EXP'='5E'] as float[64]: invalid number "5E" (want a number like 1500, 1_500, 1.5e3 or 1.5k, multipliers are k, M, G and T)`
+		if err == nil || err.Error() != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("AsUint", func(t *testing.T) {
+		got, err := env.AsUint("RATE", 16, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 1500 {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", 1500, got)
+		}
+	})
+
+	t.Run("AsFloat", func(t *testing.T) {
+		got, err := env.AsFloat("RATIO", 64, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 2500 {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", 2500.0, got)
+		}
+	})
+
+	t.Run("AsInt out of range", func(t *testing.T) {
+		_, err := env.AsInt("WORKERS", 8, opts)
+		want := `env: unable to parse ['WORKERS'='10k'] as int[8]: value out of range`
+		if err == nil || err.Error() != want || !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, err)
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var cfg struct {
+			Workers int     `env:"WORKERS"`
+			Rate    uint    `env:"RATE"`
+			Ratio   float32 `env:"RATIO"`
+		}
+		if err := env.Unmarshal(&cfg, opts); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Workers != 10e3 || cfg.Rate != 1500 || cfg.Ratio != 2500 {
+			t.Errorf("\ngot:'%#v'\n", cfg)
+		}
+	})
+
+	t.Run("NewSchema", func(t *testing.T) {
+		s, err := env.NewSchema(&struct {
+			Workers int              `env:"WORKERS,default=1k"`
+			Limits  map[string]*uint `env:"LIMITS"`
+			Ratio   float64          `env:"RATIO,si"`
+			Memory  uint64           `env:"MEMORY,size"`
+			Cache   env.ByteSize     `env:"CACHE"`
+			Timeout time.Duration    `env:"TIMEOUT"`
+			Key     []byte           `env:"KEY"`
+		}{}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"WORKERS": "WORKERS,si,default=1k",
+			"LIMITS":  "LIMITS,si",
+			"RATIO":   "RATIO,si",
+			"MEMORY":  "MEMORY,size",
+			"CACHE":   "CACHE",
+			"TIMEOUT": "TIMEOUT",
+			"KEY":     "KEY",
+		}
+		for name, tag := range want {
+			if got := s.Properties[name].Tag; got != tag {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", tag, got)
+			}
+		}
+	})
+}
+
+func TestSINumberSchema(t *testing.T) {
+	type config struct {
+		Workers int     `env:"WORKERS,si"`
+		Rate    uint    `env:"RATE,si"`
+		Ratio   float64 `env:"RATIO,si"`
+	}
+	s, err := env.NewSchema(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patterns must agree with the parsers
+	values := []string{
+		"0", "10", "-10", "10k", "1.5M", "2G", "1T", "1_500", "1_000_000", "1e3", "1.5e3", "1E2", "-2k", "0x1F", ".5k",
+		"", "k", "1x", "5m", "1e", "5E", "_1", "1e3k", "1.2.3", "1 k", "--1",
+	}
+	wholeOrRange := regexp.MustCompile(`not a whole number|out of range`)
+	for _, name := range []string{"WORKERS", "RATE", "RATIO"} {
+		re := regexp.MustCompile(s.Properties[name].Pattern)
+		for _, v := range values {
+			err := env.Unmarshal(&config{}, env.Options{Source: env.MapSource{name: v}})
+			// the pattern cannot know about whole numbers and ranges
+			if err != nil && wholeOrRange.MatchString(err.Error()) {
+				continue
+			}
+			if want, got := err == nil, re.MatchString(v); want != got {
+				t.Errorf("%s %q: parser accepts %v, pattern matches %v", name, v, want, got)
+			}
+		}
+	}
+}
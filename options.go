@@ -1,15 +1,48 @@
 package env
 
+import "reflect"
+
 const defaultTag = "env"
 
 var defaultOptions = Options{
 	Tag:      defaultTag,
 	Required: false,
+	Source:   OS,
+	FailFast: false,
+	Redact:   false,
+	Parsers:  nil,
+
+	ExtendedDurations: false,
+	SINumbers:         false,
+	LenientBools:      false,
+	PresenceBools:     false,
 }
 
 type Options struct {
 	Tag      string // default "env"
 	Required bool   // default false
+	Source   Source // default OS
+	FailFast bool   // default false, stop at the first error
+	Redact   bool   // default false, mask every value in errors (not only sensitive fields)
+
+	// Parsers override RegisterParser for a single call, default nil
+	Parsers map[reflect.Type]ParserFunc
+
+	// ExtendedDurations accepts days, weeks and ISO 8601 in every
+	// time.Duration as the extended tag option does, default false
+	ExtendedDurations bool
+
+	// SINumbers accepts k, M, G and T multipliers, underscores and exact
+	// exponents in every number as the si tag option does, default false
+	SINumbers bool
+
+	// LenientBools accepts yes/no, on/off, y/n and enabled/disabled in any
+	// case in every bool as the lenient tag option does, default false
+	LenientBools bool
+
+	// PresenceBools makes every bool that is set but empty true as the
+	// presence tag option does, default false
+	PresenceBools bool
 }
 
 func getOptions(opts ...Options) Options {
@@ -21,6 +54,30 @@ func getOptions(opts ...Options) Options {
 		if opt.Required {
 			o.Required = opt.Required
 		}
+		if opt.FailFast {
+			o.FailFast = opt.FailFast
+		}
+		if opt.Redact {
+			o.Redact = opt.Redact
+		}
+		if opt.Source != nil {
+			o.Source = opt.Source
+		}
+		if opt.Parsers != nil {
+			o.Parsers = opt.Parsers
+		}
+		if opt.ExtendedDurations {
+			o.ExtendedDurations = opt.ExtendedDurations
+		}
+		if opt.SINumbers {
+			o.SINumbers = opt.SINumbers
+		}
+		if opt.LenientBools {
+			o.LenientBools = opt.LenientBools
+		}
+		if opt.PresenceBools {
+			o.PresenceBools = opt.PresenceBools
+		}
 	}
 	return o
 }
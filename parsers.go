@@ -1,25 +1,34 @@
 package env
 
 import (
+	"encoding/base64"
 	"fmt"
-	"os"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // Required due to init cycle
 func init() {
-	parsers[reflect.Slice] = func(f reflect.Value, v string) error {
+	parsers[reflect.Slice] = func(f reflect.Value, v string, t tag, opts Options) error {
 		sl := reflect.MakeSlice(f.Type(), 0, 0)
 		if f.Type().Elem().Kind() == reflect.Uint8 {
-			sl = reflect.ValueOf([]byte(v))
+			b := []byte(v)
+			if t.Base64 {
+				var err error
+				if b, err = base64.StdEncoding.DecodeString(v); err != nil {
+					return err
+				}
+			}
+			sl = reflect.ValueOf(b).Convert(f.Type())
 		} else if len(strings.TrimSpace(v)) != 0 {
-			valCollection := strings.Split(v, ",")
+			valCollection, err := splitList(v, t)
+			if err != nil {
+				return err
+			}
 			sl = reflect.MakeSlice(f.Type(), len(valCollection), len(valCollection))
 			for i, v := range valCollection {
-				err := setValue(sl.Index(i), v)
+				err := setValue(sl.Index(i), v, t, opts)
 				if err != nil {
 					return err
 				}
@@ -29,22 +38,24 @@ func init() {
 		return nil
 	}
 
-	parsers[reflect.Map] = func(f reflect.Value, v string) error {
+	parsers[reflect.Map] = func(f reflect.Value, v string, t tag, opts Options) error {
 		mp := reflect.MakeMap(f.Type())
 		if len(strings.TrimSpace(v)) != 0 {
-			pairs := strings.Split(v, ",")
-			for _, pair := range pairs {
-				keyValues := strings.Split(pair, ":")
-				if len(keyValues) != 2 {
-					return fmt.Errorf("invalid map item: %q", pair)
-				}
+			items, err := splitMap(v, t)
+			if err != nil {
+				return err
+			}
+			for i, item := range items {
 				k := reflect.New(f.Type().Key()).Elem()
-				err := setValue(k, keyValues[0])
+				err := setValue(k, item[0], t, opts)
 				if err != nil {
 					return err
 				}
+				if !t.AllowDups && mp.MapIndex(k).IsValid() {
+					return fmt.Errorf("invalid map: item %d repeats an earlier key", i+1)
+				}
 				rv := reflect.New(f.Type().Elem()).Elem()
-				err = setValue(rv, keyValues[1])
+				err = setValue(rv, item[1], t, opts)
 				if err != nil {
 					return err
 				}
@@ -56,69 +67,83 @@ func init() {
 	}
 }
 
-type parser func(f reflect.Value, v string) error
+type parser func(f reflect.Value, v string, t tag, opts Options) error
+
+// typeParsers holds the built-in parsers for types whose kind parser does
+// not fit, they come after registered parsers and Unmarshalers but before
+// the encoding interfaces
+var typeParsers = map[reflect.Type]parser{
+	durationType: func(f reflect.Value, v string, t tag, opts Options) error {
+		d, err := parseDuration(v, t.Extended || opts.ExtendedDurations)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	},
+	timeType: func(f reflect.Value, v string, t tag, opts Options) error {
+		tm, err := parseTime(v, t.Layout)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(tm))
+		return nil
+	},
+}
 
 var parsers = map[reflect.Kind]parser{
-	reflect.String: func(f reflect.Value, v string) error {
+	reflect.String: func(f reflect.Value, v string, t tag, opts Options) error {
 		f.SetString(v)
 		return nil
 	},
-	reflect.Bool: func(f reflect.Value, v string) error {
-		val, err := strconv.ParseBool(v)
+	reflect.Bool: func(f reflect.Value, v string, t tag, opts Options) error {
+		val, err := parseBool(v, t, opts)
 		if err != nil {
 			return err
 		}
 		f.SetBool(val)
 		return nil
 	},
-	reflect.Int: func(f reflect.Value, v string) error {
-		return setInt(f, v, 32)
+	reflect.Int: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setInt(f, v, t, opts, 32)
 	},
-	reflect.Int8: func(f reflect.Value, v string) error {
-		return setInt(f, v, 8)
+	reflect.Int8: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setInt(f, v, t, opts, 8)
 	},
-	reflect.Int16: func(f reflect.Value, v string) error {
-		return setInt(f, v, 16)
+	reflect.Int16: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setInt(f, v, t, opts, 16)
 	},
-	reflect.Int32: func(f reflect.Value, v string) error {
-		return setInt(f, v, 32)
+	reflect.Int32: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setInt(f, v, t, opts, 32)
 	},
-	reflect.Int64: func(f reflect.Value, v string) error {
-		if f.Type().PkgPath() == "time" && f.Type().Name() == "Duration" {
-			d, err := time.ParseDuration(v)
-			if err != nil {
-				return err
-			}
-			f.SetInt(int64(d))
-			return nil
-		}
-		return setInt(f, v, 64)
+	reflect.Int64: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setInt(f, v, t, opts, 64)
 	},
-	reflect.Uint: func(f reflect.Value, v string) error {
-		return setUint(f, v, 32)
+	reflect.Uint: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setUint(f, v, t, opts, 32)
 	},
-	reflect.Uint8: func(f reflect.Value, v string) error {
-		return setUint(f, v, 8)
+	reflect.Uint8: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setUint(f, v, t, opts, 8)
 	},
-	reflect.Uint16: func(f reflect.Value, v string) error {
-		return setUint(f, v, 16)
+	reflect.Uint16: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setUint(f, v, t, opts, 16)
 	},
-	reflect.Uint32: func(f reflect.Value, v string) error {
-		return setUint(f, v, 32)
+	reflect.Uint32: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setUint(f, v, t, opts, 32)
 	},
-	reflect.Uint64: func(f reflect.Value, v string) error {
-		return setUint(f, v, 64)
+	reflect.Uint64: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setUint(f, v, t, opts, 64)
 	},
-	reflect.Float32: func(f reflect.Value, v string) error {
-		return setFloat(f, v, 32)
+	reflect.Float32: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setFloat(f, v, t, opts, 32)
 	},
-	reflect.Float64: func(f reflect.Value, v string) error {
-		return setFloat(f, v, 64)
+	reflect.Float64: func(f reflect.Value, v string, t tag, opts Options) error {
+		return setFloat(f, v, t, opts, 64)
 	},
 }
 
-func setInt(f reflect.Value, v string, bitSize int) error {
-	val, err := strconv.ParseInt(v, 0, bitSize)
+func setInt(f reflect.Value, v string, t tag, opts Options, bitSize int) error {
+	val, err := parseInt(v, bitSize, t, opts)
 	if err != nil {
 		return err
 	}
@@ -126,8 +151,8 @@ func setInt(f reflect.Value, v string, bitSize int) error {
 	return nil
 }
 
-func setUint(f reflect.Value, v string, bitSize int) error {
-	val, err := strconv.ParseUint(v, 0, bitSize)
+func setUint(f reflect.Value, v string, t tag, opts Options, bitSize int) error {
+	val, err := parseUint(v, bitSize, t, opts)
 	if err != nil {
 		return err
 	}
@@ -135,8 +160,8 @@ func setUint(f reflect.Value, v string, bitSize int) error {
 	return nil
 }
 
-func setFloat(f reflect.Value, v string, bitSize int) error {
-	val, err := strconv.ParseFloat(v, bitSize)
+func setFloat(f reflect.Value, v string, t tag, opts Options, bitSize int) error {
+	val, err := parseFloat(v, bitSize, t, opts)
 	if err != nil {
 		return err
 	}
@@ -144,81 +169,217 @@ func setFloat(f reflect.Value, v string, bitSize int) error {
 	return nil
 }
 
-func lookup(s string) (string, error) {
-	val, ok := os.LookupEnv(s)
+func lookup(s string, opts Options) (string, error) {
+	val, ok := opts.Source.Lookup(s)
 	if !ok {
-		return val, fmt.Errorf("env: '%s' not found", s)
+		return val, &NotFoundError{Var: s}
 	}
 	return val, nil
 }
 
-func AsString(s string) (string, error) {
-	return lookup(s)
+func AsString(s string, options ...Options) (string, error) {
+	return lookup(s, getOptions(options...))
 }
 
-func AsBool(s string) (v bool, e error) {
-	val, err := lookup(s)
+func AsBool(s string, options ...Options) (v bool, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
 	if err != nil {
 		return v, err
 	}
-	v, e = strconv.ParseBool(val)
+	v, e = parseBool(val, tag{}, opts)
 	if e != nil {
-		return v, parseError(s, val, "bool", 0)
+		return v, parseError(s, val, "bool", 0, e, opts.LenientBools, opts.Redact)
 	}
 	return v, nil
 }
 
-func AsInt(s string, bitSize int) (v int64, e error) {
-	val, err := lookup(s)
+func AsInt(s string, bitSize int, options ...Options) (v int64, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
 	if err != nil {
 		return v, err
 	}
-	v, e = strconv.ParseInt(val, 0, bitSize)
+	v, e = parseInt(val, bitSize, tag{}, opts)
 	if e != nil {
-		return v, parseError(s, val, "int", bitSize)
+		return v, parseError(s, val, "int", bitSize, e, opts.SINumbers, opts.Redact)
 	}
 	return v, nil
 }
 
-func AsDuration(s string) (v time.Duration, e error) {
-	val, err := lookup(s)
+func AsDuration(s string, options ...Options) (v time.Duration, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
 	if err != nil {
 		return v, err
 	}
-	v, e = time.ParseDuration(val)
+	v, e = parseDuration(val, opts.ExtendedDurations)
 	if e != nil {
-		return v, parseError(s, val, "duration", 0)
+		return v, parseError(s, val, "duration", 0, e, opts.ExtendedDurations, opts.Redact)
 	}
 	return v, nil
 }
 
-func AsFloat(s string, bitSize int) (v float64, e error) {
-	val, err := lookup(s)
+func AsFloat(s string, bitSize int, options ...Options) (v float64, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
 	if err != nil {
 		return v, err
 	}
-	v, e = strconv.ParseFloat(val, bitSize)
+	v, e = parseFloat(val, bitSize, tag{}, opts)
 	if e != nil {
-		return v, parseError(s, val, "float", bitSize)
+		return v, parseError(s, val, "float", bitSize, e, opts.SINumbers, opts.Redact)
 	}
 	return v, nil
 }
 
-func AsUint(s string, bitSize int) (v uint64, e error) {
-	val, err := lookup(s)
+func AsUint(s string, bitSize int, options ...Options) (v uint64, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
 	if err != nil {
 		return v, err
 	}
-	v, e = strconv.ParseUint(val, 0, bitSize)
+	v, e = parseUint(val, bitSize, tag{}, opts)
 	if e != nil {
-		return v, parseError(s, val, "uint", bitSize)
+		return v, parseError(s, val, "uint", bitSize, e, opts.SINumbers, opts.Redact)
 	}
 	return v, nil
 }
 
-func parseError(s string, v string, t string, b int) error {
+// AsTime parses the variable with layout, a Go layout or one of the names
+// the layout= tag option accepts, RFC 3339 when empty.
+func AsTime(s string, layout string, options ...Options) (v time.Time, e error) {
+	opts := getOptions(options...)
+	val, err := lookup(s, opts)
+	if err != nil {
+		return v, err
+	}
+	v, e = parseTime(val, layout)
+	if e != nil {
+		return v, parseError(s, val, "time", 0, e, false, opts.Redact)
+	}
+	return v, nil
+}
+
+// The Or variants return def when the variable is not set, a value that
+// cannot be parsed is still an error, returned along with def.
+
+func AsStringOr(s string, def string, options ...Options) (string, error) {
+	v, ok, err := LookupString(s, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsBoolOr(s string, def bool, options ...Options) (bool, error) {
+	v, ok, err := LookupBool(s, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsIntOr(s string, bitSize int, def int64, options ...Options) (int64, error) {
+	v, ok, err := LookupInt(s, bitSize, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsDurationOr(s string, def time.Duration, options ...Options) (time.Duration, error) {
+	v, ok, err := LookupDuration(s, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsFloatOr(s string, bitSize int, def float64, options ...Options) (float64, error) {
+	v, ok, err := LookupFloat(s, bitSize, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsUintOr(s string, bitSize int, def uint64, options ...Options) (uint64, error) {
+	v, ok, err := LookupUint(s, bitSize, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+func AsTimeOr(s string, layout string, def time.Time, options ...Options) (time.Time, error) {
+	v, ok, err := LookupTime(s, layout, options...)
+	if !ok || err != nil {
+		return def, err
+	}
+	return v, nil
+}
+
+// The Lookup variants report whether the variable is set, so an unset
+// variable is not an error while an unparseable one is.
+
+func LookupString(s string, options ...Options) (v string, ok bool, e error) {
+	v, e = AsString(s, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupBool(s string, options ...Options) (v bool, ok bool, e error) {
+	v, e = AsBool(s, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupInt(s string, bitSize int, options ...Options) (v int64, ok bool, e error) {
+	v, e = AsInt(s, bitSize, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupDuration(s string, options ...Options) (v time.Duration, ok bool, e error) {
+	v, e = AsDuration(s, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupFloat(s string, bitSize int, options ...Options) (v float64, ok bool, e error) {
+	v, e = AsFloat(s, bitSize, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupUint(s string, bitSize int, options ...Options) (v uint64, ok bool, e error) {
+	v, e = AsUint(s, bitSize, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+func LookupTime(s string, layout string, options ...Options) (v time.Time, ok bool, e error) {
+	v, e = AsTime(s, layout, options...)
+	ok, e = found(e)
+	return v, ok, e
+}
+
+// found turns a NotFoundError into ok == false
+func found(err error) (bool, error) {
+	if _, ok := err.(*NotFoundError); ok {
+		return false, nil
+	}
+	return true, err
+}
+
+func parseError(s string, v string, t string, b int, err error, detailed bool, redact bool) error {
 	if b != 0 {
 		t = fmt.Sprintf("%s[%d]", t, b)
 	}
-	return fmt.Errorf("env: unable to parse ['%s'='%s'] as %s", s, v, t)
+	pe := &ParseError{Var: s, Type: t, Value: v, Err: err, detailed: detailed}
+	if redact {
+		pe.redact()
+	}
+	return pe
 }
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ParserFunc parses a variable into a value of the type it is registered
+// for, see RegisterParser and Options.Parsers.
+type ParserFunc func(value string) (interface{}, error)
+
+// ParserFor adapts a typed parse function, e.g. uuid.Parse, to a ParserFunc.
+func ParserFor[T any](fn func(string) (T, error)) ParserFunc {
+	return func(value string) (interface{}, error) {
+		return fn(value)
+	}
+}
+
+// TypeOf returns the reflect.Type of T, for use as an Options.Parsers key.
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+var registry = struct {
+	sync.RWMutex
+	parsers map[reflect.Type]ParserFunc
+}{parsers: map[reflect.Type]ParserFunc{}}
+
+// RegisterParser makes fn the parser for every field of type T, including
+// types that implement Unmarshaler. It is safe for concurrent use, a later
+// registration replaces an earlier one.
+func RegisterParser[T any](fn func(string) (T, error)) {
+	RegisterParserFunc(TypeOf[T](), ParserFor(fn))
+}
+
+// RegisterParserFunc is RegisterParser for a type only known at run time,
+// fn must return values assignable to t. A nil fn removes the parser.
+func RegisterParserFunc(t reflect.Type, fn ParserFunc) {
+	registry.Lock()
+	defer registry.Unlock()
+	if fn == nil {
+		delete(registry.parsers, t)
+		return
+	}
+	registry.parsers[t] = fn
+}
+
+// parserFor returns the parser for rt from the options or the registry
+func parserFor(rt reflect.Type, opts Options) ParserFunc {
+	if fn, ok := opts.Parsers[rt]; ok {
+		return fn
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.parsers[rt]
+}
+
+func setParsed(rf reflect.Value, fn ParserFunc, val string) error {
+	v, err := fn(val)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		rf.Set(reflect.Zero(rf.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(rf.Type()) {
+		return fmt.Errorf("parser for %s returned %s", rf.Type(), rv.Type())
+	}
+	rf.Set(rv)
+	return nil
+}
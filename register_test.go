@@ -0,0 +1,211 @@
+package env_test
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+type point struct {
+	X, Y int
+}
+
+func parsePoint(v string) (point, error) {
+	var p point
+	x, y, ok := strings.Cut(v, "x")
+	if !ok {
+		return p, fmt.Errorf("missing 'x'")
+	}
+	var err error
+	if p.X, err = strconv.Atoi(x); err != nil {
+		return p, err
+	}
+	if p.Y, err = strconv.Atoi(y); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func TestRegisterParser(t *testing.T) {
+	env.RegisterParser(parsePoint)
+	env.RegisterParser(url.Parse)
+	env.RegisterParser(func(v string) (CustomURL, error) {
+		return CustomURL{Scheme: "registered", Opaque: v}, nil
+	})
+	t.Cleanup(func() {
+		env.RegisterParserFunc(env.TypeOf[point](), nil)
+		env.RegisterParserFunc(env.TypeOf[*url.URL](), nil)
+		env.RegisterParserFunc(env.TypeOf[CustomURL](), nil)
+	})
+
+	src := env.MapSource{
+		"SIZE":   "640x480",
+		"SIZES":  "1x2,3x4",
+		"URL":    "http://github.com/halorium/env",
+		"NUMBER": "42",
+		"BAD":    "640",
+	}
+	u, _ := url.Parse("http://github.com/halorium/env")
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		opts env.Options
+		err  error
+		want interface{}
+	}{
+		{
+			name: "registered struct type is not recursed into",
+			obj: &struct {
+				Size point `env:"SIZE"`
+			}{},
+			opts: env.Options{Source: src},
+			err:  nil,
+			want: &struct {
+				Size point `env:"SIZE"`
+			}{Size: point{640, 480}},
+		},
+		{
+			name: "pointer and slice of a registered type",
+			obj: &struct {
+				Size  *point  `env:"SIZE"`
+				Sizes []point `env:"SIZES"`
+			}{},
+			opts: env.Options{Source: src},
+			err:  nil,
+			want: &struct {
+				Size  *point  `env:"SIZE"`
+				Sizes []point `env:"SIZES"`
+			}{Size: &point{640, 480}, Sizes: []point{{1, 2}, {3, 4}}},
+		},
+		{
+			name: "registered pointer type",
+			obj: &struct {
+				URL *url.URL `env:"URL"`
+			}{},
+			opts: env.Options{Source: src},
+			err:  nil,
+			want: &struct {
+				URL *url.URL `env:"URL"`
+			}{URL: u},
+		},
+		{
+			name: "registered parser before Unmarshaler",
+			obj: &struct {
+				URL CustomURL `env:"URL"`
+			}{},
+			opts: env.Options{Source: src},
+			err:  nil,
+			want: &struct {
+				URL CustomURL `env:"URL"`
+			}{URL: CustomURL{Scheme: "registered", Opaque: "http://github.com/halorium/env"}},
+		},
+		{
+			name: "options override the registry",
+			obj: &struct {
+				Size point `env:"NUMBER"`
+			}{},
+			opts: env.Options{Source: src, Parsers: map[reflect.Type]env.ParserFunc{
+				env.TypeOf[point](): env.ParserFor(func(v string) (point, error) {
+					n, err := strconv.Atoi(v)
+					return point{n, n}, err
+				}),
+			}},
+			err: nil,
+			want: &struct {
+				Size point `env:"NUMBER"`
+			}{Size: point{42, 42}},
+		},
+		{
+			name: "options override a kind parser",
+			obj: &struct {
+				Number int `env:"NUMBER"`
+			}{},
+			opts: env.Options{Source: src, Parsers: map[reflect.Type]env.ParserFunc{
+				env.TypeOf[int](): func(v string) (interface{}, error) { return len(v), nil },
+			}},
+			err: nil,
+			want: &struct {
+				Number int `env:"NUMBER"`
+			}{Number: 2},
+		},
+		{
+			name: "parser error",
+			obj: &struct {
+				Size point `env:"BAD"`
+			}{},
+			opts: env.Options{Source: src},
+			err:  fmt.Errorf("env: unable to parse ['BAD'='640'] as env_test.point for field 'Size': missing 'x'"),
+			want: nil,
+		},
+		{
+			name: "parser returning the wrong type",
+			obj: &struct {
+				Number int `env:"NUMBER"`
+			}{},
+			opts: env.Options{Source: src, Parsers: map[reflect.Type]env.ParserFunc{
+				env.TypeOf[int](): func(v string) (interface{}, error) { return v, nil },
+			}},
+			err:  fmt.Errorf("env: unable to parse ['NUMBER'='42'] as int for field 'Number': parser for int returned string"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, c.opts)
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+
+	t.Run("As", func(t *testing.T) {
+		got, err := env.As[point]("SIZE", env.Options{Source: src})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (point{640, 480}); got != want {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got)
+		}
+	})
+}
+
+func TestRegisterParserConcurrent(t *testing.T) {
+	type id string
+	t.Cleanup(func() { env.RegisterParserFunc(env.TypeOf[id](), nil) })
+
+	src := env.Options{Source: env.MapSource{"ID": "a"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			env.RegisterParser(func(v string) (id, error) { return id(v), nil })
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := env.As[id]("ID", src); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
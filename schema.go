@@ -0,0 +1,314 @@
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema describing the variables of a config struct, see
+// JSONSchema.
+type Schema struct {
+	Schema     string                    `json:"$schema"`
+	Type       string                    `json:"type"`
+	Properties map[string]SchemaProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// SchemaProperty describes a single variable. Every variable is a string,
+// Pattern and Enum constrain it to what the field's parser accepts.
+type SchemaProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Format      string   `json:"format,omitempty"` // e.g. "date-time"
+	Enum        []string `json:"enum,omitempty"`
+	Default     *string  `json:"default,omitempty"`
+	WriteOnly   bool     `json:"writeOnly,omitempty"` // sensitive
+
+	GoType string `json:"x-go-type"`  // e.g. "int" or "[]string"
+	Field  string `json:"x-go-field"` // struct field path
+	Tag    string `json:"x-env-tag"`  // the full struct tag
+}
+
+// JSONSchema returns a JSON Schema document for the variables of obj, a
+// struct or a pointer to one (which may be nil), e.g. to validate
+// deployment manifests before anything runs.
+func JSONSchema(obj interface{}, options ...Options) ([]byte, error) {
+	s, err := NewSchema(obj, options...)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// NewSchema builds the Schema that JSONSchema encodes.
+func NewSchema(obj interface{}, options ...Options) (*Schema, error) {
+	// get default options and merge in any overrides
+	opts := getOptions(options...)
+
+	rt := reflect.TypeOf(obj)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, ErrInvalidType
+	}
+
+	s := &Schema{
+		Schema:     schemaDraft,
+		Type:       "object",
+		Properties: map[string]SchemaProperty{},
+	}
+
+	// walk a scratch value, nested pointers are instantiated like Unmarshal does
+	rv := reflect.New(rt).Elem()
+
+	err := walkStruct(rv, "", opts, true, func(f field) error {
+		d := describeField(f, opts)
+		p := SchemaProperty{
+			Type:        "string",
+			Description: d.Description,
+			Enum:        d.Enum,
+			WriteOnly:   d.Sensitive,
+			GoType:      d.Type,
+			Field:       d.Field,
+			Tag:         schemaTag(f, opts),
+		}
+		if len(p.Enum) == 0 {
+			p.Pattern, p.Enum = schemaPattern(f.Value.Type(), opts)
+		}
+		if rt := indirect(f.Value.Type()); rt == timeType && parserFor(rt, opts) == nil {
+			p.Format, p.Pattern = timeFormat(f.Tag.Layout)
+		} else if p.Pattern == durationPattern && (f.Tag.Extended || opts.ExtendedDurations) {
+			p.Pattern = extendedDurationPattern
+		} else if f.Tag.Size || rt == byteSizeType {
+			p.Pattern = sizePatterns[rt.Kind()]
+		} else if f.Tag.SI || opts.SINumbers {
+			p.Pattern = siPattern(p.Pattern)
+		} else if rt.Kind() == reflect.Bool && len(d.Enum) == 0 && len(p.Enum) != 0 {
+			p.Pattern, p.Enum = boolSchema(p.Enum, f.Tag.Lenient || opts.LenientBools, f.Tag.Presence || opts.PresenceBools)
+		}
+		if d.HasDefault && !d.Sensitive {
+			def := d.Default
+			p.Default = &def
+		}
+		// a default satisfies a required variable
+		if d.Required && !d.HasDefault {
+			s.Required = append(s.Required, d.Name)
+		}
+		s.Properties[d.Name] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// schemaTag returns the field's tag with the global options that apply to
+// it added, so the tag alone parses values the way the schema describes
+func schemaTag(f field, opts Options) string {
+	raw := f.StructField.Tag.Get(opts.Tag)
+	rt := f.Value.Type()
+	if opts.Required && !f.Tag.Required && !f.Tag.Optional {
+		raw = addTagOption(raw, "required")
+	}
+	if opts.ExtendedDurations && !f.Tag.Extended && containsType(rt, func(rt reflect.Type) bool { return rt == durationType }) {
+		raw = addTagOption(raw, "extended")
+	}
+	if opts.SINumbers && !f.Tag.SI && !f.Tag.Size && containsType(rt, isSINumber) {
+		raw = addTagOption(raw, "si")
+	}
+	isBool := func(rt reflect.Type) bool { return rt.Kind() == reflect.Bool }
+	if opts.LenientBools && !f.Tag.Lenient && containsType(rt, isBool) {
+		raw = addTagOption(raw, "lenient")
+	}
+	if opts.PresenceBools && !f.Tag.Presence && containsType(rt, isBool) {
+		raw = addTagOption(raw, "presence")
+	}
+	return raw
+}
+
+// isSINumber reports whether the si tag option applies to rt
+func isSINumber(rt reflect.Type) bool {
+	if rt == durationType || rt == byteSizeType {
+		return false
+	}
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// containsType reports whether rt or, through pointers, slices and maps,
+// one of its element or key types matches, []byte is a string
+func containsType(rt reflect.Type, match func(reflect.Type) bool) bool {
+	if match(rt) {
+		return true
+	}
+	switch rt.Kind() {
+	case reflect.Slice:
+		return rt.Elem().Kind() != reflect.Uint8 && containsType(rt.Elem(), match)
+	case reflect.Ptr, reflect.Array:
+		return containsType(rt.Elem(), match)
+	case reflect.Map:
+		return containsType(rt.Key(), match) || containsType(rt.Elem(), match)
+	}
+	return false
+}
+
+// addTagOption adds an option to a tag, before default= as that must stay
+// last
+func addTagOption(raw string, option string) string {
+	if i := strings.Index(raw, ",default="); i >= 0 {
+		return raw[:i] + "," + option + raw[i:]
+	}
+	return raw + "," + option
+}
+
+// schemaPattern returns the pattern or enum matching what the parser for
+// rt accepts, types with their own parser or (Text)Unmarshaler are left
+// unconstrained
+func schemaPattern(rt reflect.Type, opts Options) (string, []string) {
+	if parserFor(rt, opts) != nil {
+		return "", nil
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	pt := reflect.PtrTo(rt)
+	if parserFor(rt, opts) != nil || pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return "", nil
+	}
+	if rt.PkgPath() == "time" && rt.Name() == "Duration" {
+		return durationPattern, nil
+	}
+	if rt.Kind() == reflect.Bool {
+		// the values strconv.ParseBool accepts
+		return "", []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+	}
+	return patterns[rt.Kind()], nil
+}
+
+// timeFormat returns the format or pattern matching a time.Time layout
+func timeFormat(layout string) (string, string) {
+	switch layout {
+	case "unix", "unixmilli", "unixmicro", "unixnano":
+		return "", `^[+-]?[0-9]+$`
+	}
+	switch goLayout(layout) {
+	case time.RFC3339, time.RFC3339Nano:
+		return "date-time", ""
+	case "2006-01-02":
+		return "date", ""
+	}
+	return "", ""
+}
+
+func indirect(rt reflect.Type) reflect.Type {
+	if rt.Kind() == reflect.Ptr {
+		return rt.Elem()
+	}
+	return rt
+}
+
+const (
+	intPattern      = `^[+-]?(0[xX][_0-9A-Fa-f]+|0[bB][_01]+|0[oO]?[_0-7]*|[1-9][_0-9]*)$`
+	uintPattern     = `^(0[xX][_0-9A-Fa-f]+|0[bB][_01]+|0[oO]?[_0-7]*|[1-9][_0-9]*)$`
+	floatPattern    = `^[+-]?(([0-9][_0-9]*(\.[_0-9]*)?|\.[0-9][_0-9]*)([eE][+-]?[0-9]+)?|[iI][nN][fF]([iI][nN][iI][tT][yY])?|[nN][aA][nN])$`
+	durationPattern = `^[+-]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+	sizePattern     = `([0-9]+(\.[0-9]*)?|\.[0-9]+) *([bB]|[kKmMgGtTpPeE][iI]?[bB]?)?$`
+	intSizePattern  = `^-?` + sizePattern
+	uintSizePattern = `^` + sizePattern
+
+	siDigitsPattern = `[0-9](_?[0-9])*`
+	siNumberPattern = `[+-]?(` + siDigitsPattern + `(\.(` + siDigitsPattern + `)?)?|\.` + siDigitsPattern + `)([eE][+-]?[0-9]+|[kKMGT])?`
+
+	isoNumber               = `[0-9]+([.,][0-9]+)?`
+	isoTime                 = `T(` + isoNumber + `H(` + isoNumber + `M)?(` + isoNumber + `S)?|` + isoNumber + `M(` + isoNumber + `S)?|` + isoNumber + `S)`
+	extendedDurationPattern = `^[+-]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h|d|w))+|P(` +
+		isoNumber + `W(` + isoNumber + `D)?(` + isoTime + `)?|` + isoNumber + `D(` + isoTime + `)?|` + isoTime + `))$`
+)
+
+// siPattern extends a number pattern with the forms the si tag option adds
+func siPattern(pattern string) string {
+	switch pattern {
+	case intPattern, uintPattern, floatPattern:
+		return "^(" + pattern[1:len(pattern)-1] + "|" + siNumberPattern + ")$"
+	}
+	return pattern
+}
+
+// boolSchema returns the pattern or enum matching what parseBool accepts,
+// the lenient vocabulary is case-insensitive so it needs a pattern
+func boolSchema(enum []string, lenient bool, presence bool) (string, []string) {
+	if !lenient {
+		if presence {
+			enum = append(enum, "")
+		}
+		return "", enum
+	}
+
+	words := make([]string, 0, len(lenientBools))
+	for w := range lenientBools {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	for i, w := range words {
+		var b strings.Builder
+		for _, c := range w {
+			if u := unicode.ToUpper(c); u != c {
+				b.WriteString("[" + string(c) + string(u) + "]")
+			} else {
+				b.WriteRune(c)
+			}
+		}
+		words[i] = b.String()
+	}
+	pattern := "(" + strings.Join(words, "|") + ")"
+	if presence {
+		pattern += "?"
+	}
+	return "^" + pattern + "$", nil
+}
+
+// sizePatterns are the patterns of the kinds the size tag option applies to
+var sizePatterns = map[reflect.Kind]string{
+	reflect.Int:    intSizePattern,
+	reflect.Int8:   intSizePattern,
+	reflect.Int16:  intSizePattern,
+	reflect.Int32:  intSizePattern,
+	reflect.Int64:  intSizePattern,
+	reflect.Uint:   uintSizePattern,
+	reflect.Uint8:  uintSizePattern,
+	reflect.Uint16: uintSizePattern,
+	reflect.Uint32: uintSizePattern,
+	reflect.Uint64: uintSizePattern,
+}
+
+// patterns mirrors the parsers table for the kinds that can be described
+// by a regular expression
+var patterns = map[reflect.Kind]string{
+	reflect.Int:     intPattern,
+	reflect.Int8:    intPattern,
+	reflect.Int16:   intPattern,
+	reflect.Int32:   intPattern,
+	reflect.Int64:   intPattern,
+	reflect.Uint:    uintPattern,
+	reflect.Uint8:   uintPattern,
+	reflect.Uint16:  uintPattern,
+	reflect.Uint32:  uintPattern,
+	reflect.Uint64:  uintPattern,
+	reflect.Float32: floatPattern,
+	reflect.Float64: floatPattern,
+}
@@ -0,0 +1,130 @@
+package env_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+type schemaConfig struct {
+	Port     uint16        `env:"PORT,default=8080" desc:"HTTP listen port"`
+	Level    string        `env:"LEVEL,enum=debug|info|warn"`
+	Debug    bool          `env:"DEBUG"`
+	Ratio    float64       `env:"RATIO"`
+	Offset   int8          `env:"OFFSET"`
+	Timeout  time.Duration `env:"TIMEOUT,required"`
+	Password string        `env:"PASSWORD,required,sensitive"`
+	URL      CustomURL     `env:"URL"`
+	Tags     []string      `env:"TAGS"`
+	Cutover  time.Time     `env:"CUTOVER"`
+	Day      *time.Time    `env:"DAY,layout=DateOnly"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	b, err := env.JSONSchema((*schemaConfig)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got env.Schema
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Type != "object" || got.Schema == "" {
+		t.Errorf("\nwant:'object schema'\ngot:'%#v'\n", got)
+	}
+	if want := []string{"TIMEOUT", "PASSWORD"}; !reflect.DeepEqual(want, got.Required) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got.Required)
+	}
+
+	port := got.Properties["PORT"]
+	def := "8080"
+	want := env.SchemaProperty{
+		Type:        "string",
+		Description: "HTTP listen port",
+		Pattern:     port.Pattern,
+		Default:     &def,
+		GoType:      "uint16",
+		Field:       "Port",
+		Tag:         "PORT,default=8080",
+	}
+	if !reflect.DeepEqual(want, port) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, port)
+	}
+
+	if want := []string{"debug", "info", "warn"}; !reflect.DeepEqual(want, got.Properties["LEVEL"].Enum) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, got.Properties["LEVEL"].Enum)
+	}
+	if p := got.Properties["PASSWORD"]; !p.WriteOnly {
+		t.Errorf("\nwant:'writeOnly'\ngot:'%#v'\n", p)
+	}
+	if p := got.Properties["URL"]; p.Pattern != "" || p.Enum != nil || p.GoType != "env_test.CustomURL" {
+		t.Errorf("\nwant:'unconstrained'\ngot:'%#v'\n", p)
+	}
+	if p := got.Properties["TAGS"]; p.Pattern != "" || p.GoType != "[]string" {
+		t.Errorf("\nwant:'unconstrained'\ngot:'%#v'\n", p)
+	}
+	if p := got.Properties["CUTOVER"]; p.Format != "date-time" {
+		t.Errorf("\nwant:'date-time'\ngot:'%#v'\n", p)
+	}
+	if p := got.Properties["DAY"]; p.Format != "date" {
+		t.Errorf("\nwant:'date'\ngot:'%#v'\n", p)
+	}
+
+	// patterns must agree with the parsers
+	samples := map[string][]string{
+		"PORT":    {"0", "8080", "0x1F", "0b101", "0o17", "017", "1_000", "-1", "abc", "1.5", ""},
+		"OFFSET":  {"0", "-12", "+7", "0x_1f", "1e3", "--1"},
+		"RATIO":   {"1", "1.5", ".5", "-2e10", "1_0.5", "Inf", "-infinity", "NaN", "1.5.5", "e5"},
+		"TIMEOUT": {"0", "5s", "1h30m", "-1.5h", ".5ms", "3µs", "5", "5d", "s"},
+	}
+	parse := map[string]func(string) error{
+		"PORT":    func(v string) error { _, err := strconv.ParseUint(v, 0, 64); return err },
+		"OFFSET":  func(v string) error { _, err := strconv.ParseInt(v, 0, 64); return err },
+		"RATIO":   func(v string) error { _, err := strconv.ParseFloat(v, 64); return err },
+		"TIMEOUT": func(v string) error { _, err := time.ParseDuration(v); return err },
+	}
+	for name, values := range samples {
+		re := regexp.MustCompile(got.Properties[name].Pattern)
+		for _, v := range values {
+			if want, got := parse[name](v) == nil, re.MatchString(v); want != got {
+				t.Errorf("%s %q: parser accepts %v, pattern matches %v", name, v, want, got)
+			}
+		}
+	}
+	for _, v := range got.Properties["DEBUG"].Enum {
+		if _, err := strconv.ParseBool(v); err != nil {
+			t.Errorf("DEBUG enum %q is not a bool", v)
+		}
+	}
+}
+
+func TestSchemaRequiredOption(t *testing.T) {
+	s, err := env.NewSchema(&struct {
+		URL   string `env:"DATABASE_URL"`
+		Host  string `env:"HOST,default=localhost"`
+		Debug bool   `env:"DEBUG,optional"`
+		Port  int    `env:"PORT,required"`
+	}{}, env.Options{Required: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tag says what Options.Required does, so it parses the same alone
+	want := map[string]string{
+		"DATABASE_URL": "DATABASE_URL,required",
+		"HOST":         "HOST,required,default=localhost",
+		"DEBUG":        "DEBUG,optional",
+		"PORT":         "PORT,required",
+	}
+	for name, tag := range want {
+		if got := s.Properties[name].Tag; got != tag {
+			t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", tag, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes written with an optional SI or IEC unit,
+// e.g. "512MiB" or "10MB". The size tag option parses int and uint fields
+// the same way.
+type ByteSize uint64
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// UnmarshalENV implements Unmarshaler.
+func (b *ByteSize) UnmarshalENV(v string) error {
+	n, err := parseSizeUint(v, 64)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// MarshalENV implements Marshaler.
+func (b ByteSize) MarshalENV() (string, error) {
+	return b.String(), nil
+}
+
+// String formats the size with the largest unit that divides it exactly,
+// e.g. "512MiB", "10MB" or "1500B".
+func (b ByteSize) String() string {
+	return formatSize(uint64(b))
+}
+
+type sizeUnit struct {
+	name string
+	size uint64
+}
+
+// sizeUnits are ordered largest first, IEC before SI of the same power
+var sizeUnits = []sizeUnit{
+	{"EiB", 1 << 60}, {"EB", 1e18},
+	{"PiB", 1 << 50}, {"PB", 1e15},
+	{"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9},
+	{"MiB", 1 << 20}, {"MB", 1e6},
+	{"KiB", 1 << 10}, {"KB", 1e3},
+}
+
+const sizeFormats = "want a number of bytes with an optional unit like 1024, 512MiB or 10MB"
+
+// parseSize parses a size, the unit is case-insensitive and its trailing
+// B optional, so "512mi" is 512MiB, and may be separated from the number by
+// spaces
+func parseSize(v string) (*big.Int, error) {
+	s := strings.TrimPrefix(v, "-")
+
+	i := 0
+	for i < len(s) && ('0' <= s[i] && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	num, unit := s[:i], strings.TrimLeft(s[i:], " ")
+	if num == "" || num == "." || strings.Count(num, ".") > 1 {
+		return nil, fmt.Errorf("invalid size %q (%s)", v, sizeFormats)
+	}
+
+	mul := uint64(1)
+	if unit != "" && !strings.EqualFold(unit, "B") {
+		found := false
+		for _, u := range sizeUnits {
+			// KiB, Ki, KB or K
+			if strings.EqualFold(unit, u.name) || strings.EqualFold(unit, strings.TrimSuffix(u.name, "B")) {
+				mul, found = u.size, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid size %q: unknown unit %q (%s)", v, unit, sizeFormats)
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return nil, fmt.Errorf("invalid size %q (%s)", v, sizeFormats)
+	}
+	r.Mul(r, new(big.Rat).SetUint64(mul))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("invalid size %q: not a whole number of bytes", v)
+	}
+	n := r.Num()
+	if len(s) != len(v) {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// parseSizeInt is parseSize into the range of an int of bitSize bits
+func parseSizeInt(v string, bitSize int) (int64, error) {
+	n, err := parseSize(v)
+	if err != nil {
+		return 0, err
+	}
+	return intInRange(n, bitSize)
+}
+
+// parseSizeUint is parseSize into the range of a uint of bitSize bits
+func parseSizeUint(v string, bitSize int) (uint64, error) {
+	n, err := parseSize(v)
+	if err != nil {
+		return 0, err
+	}
+	return uintInRange(n, bitSize)
+}
+
+func formatSize(n uint64) string {
+	for _, u := range sizeUnits {
+		if n >= u.size && n%u.size == 0 {
+			return strconv.FormatUint(n/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatUint(n, 10) + "B"
+}
@@ -0,0 +1,240 @@
+package env_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+func TestByteSize(t *testing.T) {
+	cases := []struct {
+		value string
+		err   error
+		want  env.ByteSize
+	}{
+		{value: "0", err: nil, want: 0},
+		{value: "1024", err: nil, want: 1024},
+		{value: "1500B", err: nil, want: 1500},
+		{value: "10MB", err: nil, want: 10e6},
+		{value: "512MiB", err: nil, want: 512 << 20},
+		{value: "512 mib", err: nil, want: 512 << 20},
+		{value: "2Gi", err: nil, want: 2 << 30},
+		{value: "1k", err: nil, want: 1000},
+		{value: "1.5KiB", err: nil, want: 1536},
+		{value: ".5MB", err: nil, want: 500e3},
+		{value: "15EiB", err: nil, want: 15 << 60},
+		{
+			value: "16EiB",
+			err:   fmt.Errorf(`env: unable to parse ['SIZE'='16EiB'] as env.ByteSize for field 'Size': value out of range`),
+		},
+		{
+			value: "",
+			err:   fmt.Errorf(`env: unable to parse ['SIZE'=''] as env.ByteSize for field 'Size': invalid size "" (want a number of bytes with an optional unit like 1024, 512MiB or 10MB)`),
+		},
+		{
+			value: "10 parsecs",
+			err:   fmt.Errorf(`env: unable to parse ['SIZE'='10 parsecs'] as env.ByteSize for field 'Size': invalid size "10 parsecs": unknown unit "parsecs" (want a number of bytes with an optional unit like 1024, 512MiB or 10MB)`),
+		},
+		{
+			value: "1.5B",
+			err:   fmt.Errorf(`env: unable to parse ['SIZE'='1.5B'] as env.ByteSize for field 'Size': invalid size "1.5B": not a whole number of bytes`),
+		},
+		{
+			value: "-1KB",
+			err:   fmt.Errorf(`env: unable to parse ['SIZE'='-1KB'] as env.ByteSize for field 'Size': value out of range`),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			src := env.MapSource{"SIZE": c.value}
+			obj := &struct {
+				Size env.ByteSize `env:"SIZE"`
+			}{}
+			err := env.Unmarshal(obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if obj.Size != c.want {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, obj.Size)
+				}
+			}
+		})
+	}
+}
+
+func TestSizeOption(t *testing.T) {
+	src := env.MapSource{
+		"BUFFER": "64KiB",
+		"LIMIT":  "-1",
+		"CACHE":  "2GB",
+		"SIZES":  "1KiB,1MiB",
+		"HUGE":   "3GB",
+	}
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want interface{}
+	}{
+		{
+			name: "int and uint fields",
+			obj: &struct {
+				Buffer int     `env:"BUFFER,size"`
+				Limit  int64   `env:"LIMIT,size"`
+				Cache  *uint64 `env:"CACHE,size"`
+				Sizes  []int32 `env:"SIZES,size"`
+				Body   uint32  `env:"BODY,size,default=10MB"`
+			}{},
+			err: nil,
+			want: &struct {
+				Buffer int     `env:"BUFFER,size"`
+				Limit  int64   `env:"LIMIT,size"`
+				Cache  *uint64 `env:"CACHE,size"`
+				Sizes  []int32 `env:"SIZES,size"`
+				Body   uint32  `env:"BODY,size,default=10MB"`
+			}{Buffer: 64 << 10, Limit: -1, Cache: ptrUint64(2e9), Sizes: []int32{1 << 10, 1 << 20}, Body: 10e6},
+		},
+		{
+			name: "overflows the bit size",
+			obj: &struct {
+				Buffer uint16 `env:"BUFFER,size"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['BUFFER'='64KiB'] as uint16 for field 'Buffer': value out of range"),
+			want: nil,
+		},
+		{
+			name: "overflows int32",
+			obj: &struct {
+				Huge int32 `env:"HUGE,size"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['HUGE'='3GB'] as int32 for field 'Huge': value out of range"),
+			want: nil,
+		},
+		{
+			name: "negative uint",
+			obj: &struct {
+				Limit uint `env:"LIMIT,size"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['LIMIT'='-1'] as uint for field 'Limit': value out of range"),
+			want: nil,
+		},
+		{
+			name: "without the option",
+			obj: &struct {
+				Buffer int `env:"BUFFER"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['BUFFER'='64KiB'] as int for field 'Buffer': invalid syntax"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalSize(t *testing.T) {
+	type config struct {
+		Buffer int           `env:"BUFFER,size"`
+		Limit  int64         `env:"LIMIT,size"`
+		Cache  uint64        `env:"CACHE,size"`
+		Odd    uint          `env:"ODD,size"`
+		Sizes  []int32       `env:"SIZES,size"`
+		Max    env.ByteSize  `env:"MAX"`
+		Zero   *env.ByteSize `env:"ZERO"`
+	}
+	zero := env.ByteSize(0)
+	cfg := config{
+		Buffer: 64 << 10,
+		Limit:  -1 << 20,
+		Cache:  2e9,
+		Odd:    1500,
+		Sizes:  []int32{1 << 10, 3e6},
+		Max:    1<<64 - 1,
+		Zero:   &zero,
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Vars{
+		{Name: "BUFFER", Value: "64KiB"},
+		{Name: "LIMIT", Value: "-1MiB"},
+		{Name: "CACHE", Value: "2GB"},
+		{Name: "ODD", Value: "1500B"},
+		{Name: "SIZES", Value: "1KiB,3MB"},
+		{Name: "MAX", Value: "18446744073709551615B"},
+		{Name: "ZERO", Value: "0B"},
+	}
+	if !reflect.DeepEqual(want, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, vars)
+	}
+
+	var got config
+	if err := env.Unmarshal(&got, env.Options{Source: vars.Map()}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", cfg, got)
+	}
+}
+
+func TestSizeSchema(t *testing.T) {
+	s, err := env.NewSchema(&struct {
+		Buffer int          `env:"BUFFER,size"`
+		Max    env.ByteSize `env:"MAX"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patterns must agree with the parsers
+	for name, values := range map[string][]string{
+		"BUFFER": {"0", "-1", "1024", "1.5KiB", ".5k", "10 MB", "1gib", "1x", "KB", "1 iB", "--1", "1e3"},
+		"MAX":    {"0", "1024", "512MiB", "1.5KiB", "10 mb", "-1", "1x", "", "Ki"},
+	} {
+		re := regexp.MustCompile(s.Properties[name].Pattern)
+		for _, v := range values {
+			err := env.Unmarshal(&struct {
+				Buffer int          `env:"BUFFER,size"`
+				Max    env.ByteSize `env:"MAX"`
+			}{}, env.Options{Source: env.MapSource{name: v}})
+			if want, got := err == nil, re.MatchString(v); want != got {
+				t.Errorf("%s %q: parser accepts %v, pattern matches %v", name, v, want, got)
+			}
+		}
+	}
+}
+
+func ptrUint64(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Source is where Unmarshal and the As* helpers look up variables.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// Enumerator is implemented by sources that can list the keys they hold.
+type Enumerator interface {
+	Keys() []string
+}
+
+// OS is the process environment, the default Source.
+var OS Source = osSource{}
+
+type osSource struct{}
+
+func (osSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (osSource) Keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		// skip malformed and windows drive entries ("=C:=C:\")
+		if i := strings.Index(kv, "="); i > 0 {
+			keys = append(keys, kv[:i])
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MapSource is a Source backed by a map of variable names to values.
+type MapSource map[string]string
+
+func (m MapSource) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m MapSource) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NamedSource labels a Source, e.g. so a Chain can report which layer
+// supplied a value.
+type NamedSource struct {
+	Name   string
+	Source Source
+}
+
+func Named(name string, s Source) NamedSource {
+	return NamedSource{Name: name, Source: s}
+}
+
+func (n NamedSource) Lookup(key string) (string, bool) {
+	return n.Source.Lookup(key)
+}
+
+func (n NamedSource) Keys() []string {
+	if e, ok := n.Source.(Enumerator); ok {
+		return e.Keys()
+	}
+	return nil
+}
+
+func (n NamedSource) String() string {
+	return n.Name
+}
+
+// Chain consults its sources in order, the first source holding a key wins.
+// List the highest precedence source first, e.g.
+//
+//	env.Chain{env.OS, hostFile, dotenvFile, defaults}
+type Chain []Source
+
+func (c Chain) Lookup(key string) (string, bool) {
+	for _, s := range c {
+		if v, ok := s.Lookup(key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the union of the keys of every source that is an Enumerator.
+func (c Chain) Keys() []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, s := range c {
+		e, ok := s.(Enumerator)
+		if !ok {
+			continue
+		}
+		for _, k := range e.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Origin returns the source that supplies key.
+func (c Chain) Origin(key string) (Source, bool) {
+	for _, s := range c {
+		if _, ok := s.Lookup(key); ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Origins maps every key listed by Keys to the source that supplies it.
+func (c Chain) Origins() map[string]Source {
+	origins := map[string]Source{}
+	for _, k := range c.Keys() {
+		if s, ok := c.Origin(k); ok {
+			origins[k] = s
+		}
+	}
+	return origins
+}
@@ -0,0 +1,136 @@
+package env_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/halorium/env"
+)
+
+func TestSources(t *testing.T) {
+	cases := []struct {
+		name string
+		run  runFunc
+		err  error
+		want interface{}
+	}{
+		{
+			name: "Unmarshal from MapSource",
+			run: func(t *testing.T) (interface{}, error) {
+				var cfg struct {
+					String string         `env:"STRING"`
+					Map    map[string]int `env:"MAP"`
+				}
+				src := env.MapSource{"STRING": "string_val", "MAP": "one:1"}
+				err := env.Unmarshal(&cfg, env.Options{Source: src})
+				return []interface{}{cfg.String, cfg.Map}, err
+			},
+			err:  nil,
+			want: []interface{}{"string_val", map[string]int{"one": 1}},
+		},
+		{
+			name: "Unmarshal from MapSource ignores os environment",
+			run: func(t *testing.T) (interface{}, error) {
+				t.Setenv("STRING", "os_val")
+				var cfg struct {
+					String string `env:"STRING"`
+				}
+				err := env.Unmarshal(&cfg, env.Options{Source: env.MapSource{}})
+				return cfg.String, err
+			},
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "AsInt from MapSource",
+			run: func(t *testing.T) (interface{}, error) {
+				return env.AsInt("INT", 64, env.Options{Source: env.MapSource{"INT": "7"}})
+			},
+			err:  nil,
+			want: int64(7),
+		},
+		{
+			name: "MapSource keys are sorted",
+			run: func(t *testing.T) (interface{}, error) {
+				return env.MapSource{"B": "", "A": "", "C": ""}.Keys(), nil
+			},
+			err:  nil,
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name: "OS source lists environment keys",
+			run: func(t *testing.T) (interface{}, error) {
+				t.Setenv("ENV_SOURCE_KEY", "val")
+				for _, k := range env.OS.(env.Enumerator).Keys() {
+					if k == "ENV_SOURCE_KEY" {
+						return true, nil
+					}
+				}
+				return false, nil
+			},
+			err:  nil,
+			want: true,
+		},
+		{
+			name: "Chain first source wins",
+			run: func(t *testing.T) (interface{}, error) {
+				var cfg struct {
+					Host string `env:"HOST"`
+					Port int    `env:"PORT"`
+				}
+				src := env.Chain{
+					env.MapSource{"HOST": "override"},
+					env.MapSource{"HOST": "default", "PORT": "8080"},
+				}
+				err := env.Unmarshal(&cfg, env.Options{Source: src})
+				return []interface{}{cfg.Host, cfg.Port}, err
+			},
+			err:  nil,
+			want: []interface{}{"override", 8080},
+		},
+		{
+			name: "Chain AsString falls through layers",
+			run: func(t *testing.T) (interface{}, error) {
+				src := env.Chain{env.MapSource{}, env.MapSource{"HOST": "default"}}
+				return env.AsString("HOST", env.Options{Source: src})
+			},
+			err:  nil,
+			want: "default",
+		},
+		{
+			name: "Chain reports origin of each key",
+			run: func(t *testing.T) (interface{}, error) {
+				src := env.Chain{
+					env.Named("env", env.MapSource{"HOST": "override"}),
+					env.Named("defaults", env.MapSource{"HOST": "default", "PORT": "8080"}),
+				}
+				names := map[string]string{}
+				for k, s := range src.Origins() {
+					names[k] = s.(env.NamedSource).Name
+				}
+				return names, nil
+			},
+			err:  nil,
+			want: map[string]string{"HOST": "env", "PORT": "defaults"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.run(t)
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tag is a parsed struct tag, e.g. `env:"PORT,default=8080"`
+type tag struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	Required   bool
+	Optional   bool
+	Sensitive  bool
+	Enum       []string // allowed values, enum=a|b|c
+	Base64     bool     // base64 encoded BinaryUnmarshaler or []byte
+	Layout     string   // time.Time layout or layout name, layout=RFC1123
+	Extended   bool     // extended time.Duration syntax, e.g. 30d or P1DT12H
+	Size       bool     // int or uint written as a byte size, e.g. 512MiB
+	SI         bool     // number with multipliers and underscores, e.g. 10k or 1_500
+	Lenient    bool     // bool also written as yes/no, on/off, y/n or enabled/disabled
+	Presence   bool     // bool that is true when set but empty, e.g. DEBUG=
+	Sep        string   // list separator, a literal or space, tab, newline or whitespace
+	Trim       bool     // trim spaces around list elements
+	KVSep      string   // map key/value separator, default ":"
+	AllowDups  bool     // map keys may repeat, the last value wins
+}
+
+// parseTag splits a struct tag into the variable name and its options.
+// default= must be the last option as its value may contain commas
+// (e.g. `env:"HOSTS,default=a,b,c"`).
+func parseTag(s string) (tag, error) {
+	parts := strings.Split(s, ",")
+	t := tag{Name: strings.TrimSpace(parts[0])}
+
+options:
+	for i := 1; i < len(parts); i++ {
+		key, value := parts[i], ""
+		if j := strings.Index(key, "="); j >= 0 {
+			key, value = key[:j], key[j+1:]
+		}
+
+		switch strings.TrimSpace(key) {
+		case "default":
+			t.Default = strings.Join(append([]string{value}, parts[i+1:]...), ",")
+			t.HasDefault = true
+			break options
+		case "required":
+			t.Required = true
+		case "optional":
+			t.Optional = true
+		case "sensitive", "secret":
+			t.Sensitive = true
+		case "enum":
+			t.Enum = strings.Split(value, "|")
+		case "base64":
+			t.Base64 = true
+		case "layout":
+			t.Layout = value
+		case "extended":
+			t.Extended = true
+		case "size":
+			t.Size = true
+		case "si":
+			t.SI = true
+		case "lenient":
+			t.Lenient = true
+		case "presence":
+			t.Presence = true
+		case "sep":
+			t.Sep = value
+		case "trim":
+			t.Trim = true
+		case "kvsep":
+			t.KVSep = value
+		case "allowdups":
+			t.AllowDups = true
+		default:
+			return t, fmt.Errorf("env: unknown option %q in tag %q", parts[i], s)
+		}
+	}
+
+	if t.Required && t.Optional {
+		return t, fmt.Errorf("env: tag %q cannot be both required and optional", s)
+	}
+	return t, nil
+}
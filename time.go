@@ -0,0 +1,79 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// layouts are the names the layout= tag option accepts in place of a Go
+// layout, anything else is used as a layout itself. Layouts containing a
+// comma (RFC1123, RFC850, ...) can only be given by name.
+var layouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// parseTime parses v with the named or Go layout, RFC 3339 by default.
+// Unix timestamps are returned in UTC.
+func parseTime(v string, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli", "unixmicro", "unixnano":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch layout {
+		case "unix":
+			return time.Unix(n, 0).UTC(), nil
+		case "unixmilli":
+			return time.UnixMilli(n).UTC(), nil
+		case "unixmicro":
+			return time.UnixMicro(n).UTC(), nil
+		}
+		return time.Unix(0, n).UTC(), nil
+	}
+	return time.Parse(goLayout(layout), v)
+}
+
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixmicro":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case "unixnano":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	return t.Format(goLayout(layout))
+}
+
+func goLayout(layout string) string {
+	if layout == "" {
+		return time.RFC3339
+	}
+	if l, ok := layouts[layout]; ok {
+		return l
+	}
+	return layout
+}
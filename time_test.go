@@ -0,0 +1,253 @@
+package env_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/halorium/env"
+)
+
+func TestTime(t *testing.T) {
+	src := env.MapSource{
+		"RFC3339":  "2024-02-29T12:30:00+01:00",
+		"RFC1123":  "Thu, 29 Feb 2024 12:30:00 UTC",
+		"DATE":     "2024-02-29",
+		"UNIX":     "1709209800",
+		"MILLIS":   "1709209800123",
+		"CUSTOM":   "29/02/2024 12h30",
+		"WINDOWS":  "2024-02-29,2024-03-01",
+		"INVALID":  "yesterday",
+		"NEGATIVE": "-1",
+	}
+	utc := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		obj  interface{}
+		err  error
+		want interface{}
+	}{
+		{
+			name: "RFC 3339 by default",
+			obj: &struct {
+				Time time.Time `env:"RFC3339"`
+			}{},
+			err: nil,
+			want: &struct {
+				Time time.Time `env:"RFC3339"`
+			}{Time: time.Date(2024, 2, 29, 12, 30, 0, 0, time.FixedZone("", 3600))},
+		},
+		{
+			name: "named layouts",
+			obj: &struct {
+				RFC1123 time.Time  `env:"RFC1123,layout=RFC1123"`
+				Date    *time.Time `env:"DATE,layout=DateOnly"`
+			}{},
+			err: nil,
+			want: &struct {
+				RFC1123 time.Time  `env:"RFC1123,layout=RFC1123"`
+				Date    *time.Time `env:"DATE,layout=DateOnly"`
+			}{RFC1123: utc, Date: ptrTime(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))},
+		},
+		{
+			name: "unix timestamps",
+			obj: &struct {
+				Unix     time.Time `env:"UNIX,layout=unix"`
+				Millis   time.Time `env:"MILLIS,layout=unixmilli"`
+				Negative time.Time `env:"NEGATIVE,layout=unixnano"`
+			}{},
+			err: nil,
+			want: &struct {
+				Unix     time.Time `env:"UNIX,layout=unix"`
+				Millis   time.Time `env:"MILLIS,layout=unixmilli"`
+				Negative time.Time `env:"NEGATIVE,layout=unixnano"`
+			}{Unix: utc, Millis: utc.Add(123 * time.Millisecond), Negative: time.Unix(0, -1).UTC()},
+		},
+		{
+			name: "Go layout",
+			obj: &struct {
+				Time time.Time `env:"CUSTOM,layout=02/01/2006 15h04"`
+			}{},
+			err: nil,
+			want: &struct {
+				Time time.Time `env:"CUSTOM,layout=02/01/2006 15h04"`
+			}{Time: utc},
+		},
+		{
+			name: "slice with layout",
+			obj: &struct {
+				Windows []time.Time `env:"WINDOWS,layout=DateOnly"`
+			}{},
+			err: nil,
+			want: &struct {
+				Windows []time.Time `env:"WINDOWS,layout=DateOnly"`
+			}{Windows: []time.Time{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name: "default with layout",
+			obj: &struct {
+				Time time.Time `env:"MISSING,layout=DateOnly,default=2024-02-29"`
+			}{},
+			err: nil,
+			want: &struct {
+				Time time.Time `env:"MISSING,layout=DateOnly,default=2024-02-29"`
+			}{Time: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "invalid time",
+			obj: &struct {
+				Time time.Time `env:"INVALID,layout=DateOnly"`
+			}{},
+			err:  fmt.Errorf(`env: unable to parse ['INVALID'='yesterday'] as time.Time for field 'Time': parsing time "yesterday" as "2006-01-02": cannot parse "yesterday" as "2006"`),
+			want: nil,
+		},
+		{
+			name: "invalid unix timestamp",
+			obj: &struct {
+				Time time.Time `env:"DATE,layout=unix"`
+			}{},
+			err:  fmt.Errorf("env: unable to parse ['DATE'='2024-02-29'] as time.Time for field 'Time': invalid syntax"),
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := env.Unmarshal(c.obj, env.Options{Source: src})
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, c.obj) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, c.obj)
+				}
+			}
+		})
+	}
+}
+
+func TestAsTime(t *testing.T) {
+	src := env.Options{Source: env.MapSource{
+		"CUTOVER": "2024-02-29T12:30:00Z",
+		"DAY":     "2024-02-29",
+	}}
+	utc := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		run  runFunc
+		err  error
+		want interface{}
+	}{
+		{
+			name: "AsTime not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsTime("CUTOVER", "") },
+			err:  fmt.Errorf("env: 'CUTOVER' not found"),
+			want: nil,
+		},
+		{
+			name: "AsTime default layout",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsTime("CUTOVER", "", src) },
+			err:  nil,
+			want: utc,
+		},
+		{
+			name: "AsTime named layout",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsTime("DAY", "DateOnly", src) },
+			err:  nil,
+			want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "AsTime invalid",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsTime("DAY", "unix", src) },
+			err:  fmt.Errorf("env: unable to parse ['DAY'='2024-02-29'] as time"),
+			want: nil,
+		},
+		{
+			name: "AsTimeOr not found",
+			run:  func(t *testing.T) (interface{}, error) { return env.AsTimeOr("MISSING", "", fallback, src) },
+			err:  nil,
+			want: fallback,
+		},
+		{
+			name: "LookupTime set",
+			run: func(t *testing.T) (interface{}, error) {
+				v, ok, err := env.LookupTime("CUTOVER", time.RFC3339, src)
+				return found{v, ok}, err
+			},
+			err:  nil,
+			want: found{utc, true},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.run(t)
+
+			if err != nil && c.err != nil {
+				if err.Error() != c.err.Error() {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err.Error(), err.Error())
+				}
+			} else if err != c.err {
+				t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.err, err)
+			}
+
+			if err == nil && c.err == nil {
+				if !reflect.DeepEqual(c.want, got) {
+					t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalTime(t *testing.T) {
+	type config struct {
+		Cutover time.Time   `env:"CUTOVER"`
+		Day     time.Time   `env:"DAY,layout=DateOnly"`
+		Unix    *time.Time  `env:"UNIX,layout=unixmilli"`
+		Windows []time.Time `env:"WINDOWS,layout=Kitchen"`
+	}
+	utc := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	cfg := config{
+		Cutover: utc,
+		Day:     time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		Unix:    &utc,
+		Windows: []time.Time{time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC), time.Date(0, 1, 1, 2, 30, 0, 0, time.UTC)},
+	}
+
+	vars, err := env.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := env.Vars{
+		{Name: "CUTOVER", Value: "2024-02-29T12:30:00Z"},
+		{Name: "DAY", Value: "2024-02-29"},
+		{Name: "UNIX", Value: "1709209800000"},
+		{Name: "WINDOWS", Value: "10:00PM,2:30AM"},
+	}
+	if !reflect.DeepEqual(want, vars) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", want, vars)
+	}
+
+	var got config
+	if err := env.Unmarshal(&got, env.Options{Source: vars.Map()}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, got) {
+		t.Errorf("\nwant:'%#v'\ngot:'%#v'\n", cfg, got)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -1,10 +1,11 @@
 package env
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
+	"strings"
 )
 
 var ErrInvalidType = errors.New("must be a pointer to a non-nil struct")
@@ -24,10 +25,12 @@ func Unmarshal(obj interface{}, options ...Options) error {
 	}
 
 	// recurse the struct and set env fields
-	return parseStruct(obj, opts)
+	return parseStruct(obj, "", opts)
 }
 
-func parseStruct(obj interface{}, opts Options) error {
+// parseStruct sets the tagged fields of obj, path is the field path of obj
+// from the value passed to Unmarshal
+func parseStruct(obj interface{}, path string, opts Options) error {
 	rv := reflect.ValueOf(obj)
 	rt := rv.Type()
 
@@ -40,62 +43,168 @@ func parseStruct(obj interface{}, opts Options) error {
 		return fmt.Errorf("object must be a struct")
 	}
 
+	return walkStruct(rv, path, opts, true, func(f field) error {
+		return parseField(f, opts)
+	})
+}
+
+// field is a tagged field found by walkStruct
+type field struct {
+	Value       reflect.Value
+	StructField reflect.StructField
+	Path        string // struct field path, e.g. "DB.URL"
+	Tag         tag
+}
+
+// walkStruct calls fn for every tagged field of the struct rv, recursing
+// into nested structs. Nil pointers to structs are instantiated when alloc
+// is set and skipped otherwise. Unless opts.FailFast is set every field is
+// visited and the failures are returned together as Errors.
+func walkStruct(rv reflect.Value, path string, opts Options, alloc bool, fn func(f field) error) error {
+	var errs Errors
+
 	// iterate over struct fields
 	for i := 0; i < rv.NumField(); i++ {
 		rf := rv.Field(i)
 		rsf := rv.Type().Field(i)
+		fieldPath := joinPath(path, rsf.Name)
 
 		// ignore non exported fields
 		if !rf.CanSet() {
 			continue
 		}
 
+		// fields without a tag or explicitly ignored are skipped, the tag
+		// is parsed first as the base64 option makes a struct a leaf
+		raw := rsf.Tag.Get(opts.Tag)
+		tagged := raw != "" && raw != "-"
+		var t tag
+		var tagErr error
+		if tagged {
+			t, tagErr = parseTag(raw)
+		}
+
 		// if pointer to struct or nil struct (instantiate it)
-		if rf.Kind() == reflect.Ptr && rf.Type().Elem().Kind() == reflect.Struct {
+		if rf.Kind() == reflect.Ptr && rf.Type().Elem().Kind() == reflect.Struct && !isLeaf(rf.Type().Elem(), tagged, t, opts) {
 			if rf.IsNil() {
+				if !alloc {
+					continue
+				}
 				// nil pointer to struct: create a zero instance
 				rf.Set(reflect.New(rf.Type().Elem()))
 			}
 			rf = rf.Elem()
 		}
 
-		// if struct we need to recurse (unless implements Unmarshaler)
-		if rf.Kind() == reflect.Struct && asUnmarshaler(rf) == nil {
-			rfi := rf.Addr().Interface()
-			err := parseStruct(rfi, opts)
-			if err != nil {
-				return err
+		var err error
+
+		// if struct we need to recurse (unless it is parsed as a whole)
+		if rf.Kind() == reflect.Struct && !isLeaf(rf.Type(), tagged, t, opts) {
+			err = walkStruct(rf, fieldPath, opts, alloc, fn)
+		} else if tagged {
+			err = tagErr
+			if err == nil {
+				err = fn(field{Value: rf, StructField: rsf, Path: fieldPath, Tag: t})
 			}
-			continue
 		}
 
-		// ignore fields without a tag or explicitly ignored
-		if rsf.Tag.Get(opts.Tag) == "-" || rsf.Tag.Get(opts.Tag) == "" {
-			continue
+		if err != nil {
+			if opts.FailFast {
+				return err
+			}
+			errs.add(err)
 		}
+	}
 
-		tag := rsf.Tag.Get(opts.Tag)
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
 
-		val, ok := os.LookupEnv(tag)
-		if !ok {
-			if opts.Required {
-				return fmt.Errorf("'%s' is required", tag)
+// isLeaf reports whether a struct type is set from a single variable
+// rather than recursed into. Only the unmarshal side decides, a config
+// struct that can merely be marshaled (e.g. for logging) is still walked,
+// and Marshal walks the same way. Untagged fields are only leaves for
+// Unmarshaler, a sub-struct that happens to implement UnmarshalText (e.g.
+// for JSON) keeps its tagged fields.
+func isLeaf(rt reflect.Type, tagged bool, t tag, opts Options) bool {
+	pt := reflect.PtrTo(rt)
+	if !tagged {
+		return pt.Implements(unmarshalerType)
+	}
+	_, builtin := typeParsers[rt]
+	return pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType) ||
+		t.Base64 && pt.Implements(binaryUnmarshalerType) ||
+		parserFor(rt, opts) != nil || parserFor(pt, opts) != nil || builtin
+}
+
+func parseField(f field, opts Options) error {
+	tag, rf := f.Tag, f.Value
+
+	val, ok := opts.Source.Lookup(tag.Name)
+	isDefault := false
+	if !ok {
+		if !tag.HasDefault {
+			if tag.Required || opts.Required && !tag.Optional {
+				return &RequiredError{Var: tag.Name, Field: f.Path}
 			}
 			// skip it
-			continue
+			return nil
 		}
+		// the default goes through the same parsing as a set value
+		val, isDefault = tag.Default, true
+	}
 
-		// now we can parse
-		err := setValue(rf, val)
-		if err != nil {
-			return err
+	// now we can parse
+	err := checkEnum(val, tag.Enum)
+	if err == nil {
+		err = setValue(rf, val, tag, opts)
+	}
+	if err != nil {
+		return fieldError(err, tag, f.Path, rf.Type(), val, isDefault, opts)
+	}
+	return nil
+}
+
+func checkEnum(val string, enum []string) error {
+	if len(enum) == 0 {
+		return nil
+	}
+	for _, e := range enum {
+		if val == e {
+			return nil
 		}
 	}
+	return fmt.Errorf("must be one of %s", strings.Join(enum, ", "))
+}
 
-	return nil
+// fieldError attaches the field details to an error from setValue
+func fieldError(err error, tag tag, fieldPath string, rt reflect.Type, val string, isDefault bool, opts Options) error {
+	var ute *UnsupportedTypeError
+	if errors.As(err, &ute) {
+		return &UnsupportedTypeError{Field: fieldPath, Type: ute.Type}
+	}
+	pe := &ParseError{Var: tag.Name, Field: fieldPath, Type: rt.String(), Value: val, Default: isDefault, Err: err}
+	if tag.Sensitive || opts.Redact {
+		pe.redact()
+	}
+	return pe
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
 }
 
-func setValue(rf reflect.Value, val string) error {
+func setValue(rf reflect.Value, val string, t tag, opts Options) error {
+	// check for a registered parser
+	if fn := parserFor(rf.Type(), opts); fn != nil {
+		return setParsed(rf, fn, val)
+	}
+
 	// check for custom UnmarshalENV function
 	if f := asUnmarshaler(rf); f != nil {
 		return f.UnmarshalENV(val)
@@ -107,11 +216,34 @@ func setValue(rf reflect.Value, val string) error {
 			rf.Set(reflect.New(rf.Type().Elem()))
 		}
 		rf = rf.Elem()
+
+		if fn := parserFor(rf.Type(), opts); fn != nil {
+			return setParsed(rf, fn, val)
+		}
+	}
+
+	// built-in types come before their encoding interfaces, e.g. time.Time
+	if setter, ok := typeParsers[rf.Type()]; ok {
+		return setter(rf, val, t, opts)
+	}
+
+	// fall back to the encoding interfaces
+	if t.Base64 {
+		if f := asBinaryUnmarshaler(rf); f != nil {
+			b, err := base64.StdEncoding.DecodeString(val)
+			if err != nil {
+				return err
+			}
+			return f.UnmarshalBinary(b)
+		}
+	}
+	if f := asTextUnmarshaler(rf); f != nil {
+		return f.UnmarshalText([]byte(val))
 	}
 
 	setter, ok := parsers[rf.Type().Kind()]
 	if !ok {
-		return fmt.Errorf("field:'%s' is an unsupported type:'%s'", rf, rf.Type().String())
+		return &UnsupportedTypeError{Type: rf.Type()}
 	}
-	return setter(rf, val)
+	return setter(rf, val, t, opts)
 }
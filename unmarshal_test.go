@@ -375,7 +375,7 @@ func TestUnmarshal(t *testing.T) {
 			}{},
 			setEnv: func(t *testing.T) {},
 			opts:   env.Options{Required: true},
-			err:    RequiredErr("STRING"),
+			err:    RequiredErr("STRING", "String"),
 			want:   nil,
 		},
 		{
@@ -509,6 +509,146 @@ func TestUnmarshal(t *testing.T) {
 				URL CustomURL `env:"URL"`
 			}{URL: getCustomURL()},
 		},
+		{
+			name: "default used when env not set",
+			obj: &struct {
+				Int  int            `env:"INT,default=8080"`
+				List []string       `env:"LIST,default=one,two"`
+				Map  map[string]int `env:"MAP,default=one:1,two:2"`
+				URL  CustomURL      `env:"URL,default=http://github.com/halorium/env"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{Required: true},
+			err:    nil,
+			want: &struct {
+				Int  int            `env:"INT,default=8080"`
+				List []string       `env:"LIST,default=one,two"`
+				Map  map[string]int `env:"MAP,default=one:1,two:2"`
+				URL  CustomURL      `env:"URL,default=http://github.com/halorium/env"`
+			}{
+				Int:  8080,
+				List: []string{"one", "two"},
+				Map:  map[string]int{"one": 1, "two": 2},
+				URL:  getCustomURL(),
+			},
+		},
+		{
+			name: "default ignored when env set",
+			obj: &struct {
+				Int int `env:"INT,default=8080"`
+			}{},
+			setEnv: func(t *testing.T) {
+				t.Setenv("INT", "1")
+			},
+			opts: env.Options{},
+			err:  nil,
+			want: &struct {
+				Int int `env:"INT,default=8080"`
+			}{Int: 1},
+		},
+		{
+			name: "invalid default",
+			obj: &struct {
+				Int int `env:"INT,default=abc"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    fmt.Errorf(`env: unable to parse default ['INT'='abc'] as int for field 'Int': invalid syntax`),
+			want:   nil,
+		},
+		{
+			name: "required tag option error",
+			obj: &struct {
+				String string `env:"STRING"`
+				URL    string `env:"URL,required"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    RequiredErr("URL", "URL"),
+			want:   nil,
+		},
+		{
+			name: "required tag option error on nested struct",
+			obj: &struct {
+				DB struct {
+					URL string `env:"DB_URL,required"`
+				}
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    RequiredErr("DB_URL", "DB.URL"),
+			want:   nil,
+		},
+		{
+			name: "required tag option error on embedded struct",
+			obj: &struct {
+				EmbeddedStruct
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{Required: true},
+			err:    RequiredErr("EMBEDDED", "EmbeddedStruct.String"),
+			want:   nil,
+		},
+		{
+			name: "optional tag option overrides required option",
+			obj: &struct {
+				String string `env:"STRING,optional"`
+				URL    string `env:"URL"`
+			}{},
+			setEnv: func(t *testing.T) {
+				t.Setenv("URL", "url_val")
+			},
+			opts: env.Options{Required: true},
+			err:  nil,
+			want: &struct {
+				String string `env:"STRING,optional"`
+				URL    string `env:"URL"`
+			}{URL: "url_val"},
+		},
+		{
+			name: "required and optional tag options conflict",
+			obj: &struct {
+				String string `env:"STRING,required,optional"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    fmt.Errorf(`env: tag "STRING,required,optional" cannot be both required and optional`),
+			want:   nil,
+		},
+		{
+			name: "enum tag option",
+			obj: &struct {
+				Level string `env:"LEVEL,enum=debug|info,default=info"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    nil,
+			want: &struct {
+				Level string `env:"LEVEL,enum=debug|info,default=info"`
+			}{Level: "info"},
+		},
+		{
+			name: "enum tag option error",
+			obj: &struct {
+				Level string `env:"LEVEL,enum=debug|info"`
+			}{},
+			setEnv: func(t *testing.T) {
+				t.Setenv("LEVEL", "trace")
+			},
+			opts: env.Options{},
+			err:  fmt.Errorf("env: unable to parse ['LEVEL'='trace'] as string for field 'Level': must be one of debug, info"),
+			want: nil,
+		},
+		{
+			name: "unknown tag option",
+			obj: &struct {
+				Int int `env:"INT,bogus"`
+			}{},
+			setEnv: func(t *testing.T) {},
+			opts:   env.Options{},
+			err:    fmt.Errorf(`env: unknown option "bogus" in tag "INT,bogus"`),
+			want:   nil,
+		},
 	}
 
 	for _, c := range cases {
@@ -600,6 +740,6 @@ func (c *CustomURL) UnmarshalENV(v string) error {
 	return nil
 }
 
-func RequiredErr(v string) error {
-	return fmt.Errorf("'%s' is required", v)
+func RequiredErr(v string, field string) error {
+	return fmt.Errorf("env: '%s' is required by field '%s'", v, field)
 }
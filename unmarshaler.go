@@ -1,12 +1,38 @@
 package env
 
-import "reflect"
+import (
+	"encoding"
+	"reflect"
+)
 
 type Unmarshaler interface {
 	UnmarshalENV(value string) error
 }
 
+var (
+	unmarshalerType       = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
 func asUnmarshaler(rv reflect.Value) Unmarshaler {
+	u, _ := addrInterface(rv).(Unmarshaler)
+	return u
+}
+
+func asTextUnmarshaler(rv reflect.Value) encoding.TextUnmarshaler {
+	u, _ := addrInterface(rv).(encoding.TextUnmarshaler)
+	return u
+}
+
+func asBinaryUnmarshaler(rv reflect.Value) encoding.BinaryUnmarshaler {
+	u, _ := addrInterface(rv).(encoding.BinaryUnmarshaler)
+	return u
+}
+
+// addrInterface returns a pointer to the value of rv as an interface,
+// instantiating nil pointers, so pointer receiver methods can be found
+func addrInterface(rv reflect.Value) interface{} {
 	if rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
 			rv.Set(reflect.New(rv.Type().Elem()))
@@ -14,12 +40,8 @@ func asUnmarshaler(rv reflect.Value) Unmarshaler {
 	} else if rv.CanAddr() {
 		rv = rv.Addr()
 	}
-	if rv.CanInterface() {
-		u, ok := rv.Interface().(Unmarshaler)
-		if !ok {
-			return nil
-		}
-		return u
+	if !rv.CanInterface() {
+		return nil
 	}
-	return nil
+	return rv.Interface()
 }
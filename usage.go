@@ -0,0 +1,59 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Usage prints the variables of obj in the style of flag.PrintDefaults,
+// noting for each whether it is currently set in the options' Source, e.g.
+// (as Go strings to show the indentation):
+//
+//	"  PORT int\n"
+//	"    \tHTTP listen port (default 8080, set)\n"
+//	"  DATABASE_URL string\n"
+//	"    \tPostgres connection URL (required, not set)\n"
+func Usage(w io.Writer, obj interface{}, options ...Options) error {
+	ds, err := Describe(obj, options...)
+	if err != nil {
+		return err
+	}
+	opts := getOptions(options...)
+
+	bw := bufio.NewWriter(w)
+	for _, d := range ds {
+		fmt.Fprintf(bw, "  %s %s\n", d.Name, d.Type)
+
+		var attrs []string
+		if len(d.Enum) != 0 {
+			attrs = append(attrs, "one of "+strings.Join(d.Enum, "|"))
+		}
+		if d.HasDefault {
+			def := d.displayDefault()
+			if d.Type == "string" && !d.Sensitive {
+				def = fmt.Sprintf("%q", def)
+			}
+			attrs = append(attrs, "default "+def)
+		}
+		if d.Required {
+			attrs = append(attrs, "required")
+		}
+		if d.Sensitive {
+			attrs = append(attrs, "sensitive")
+		}
+		if _, ok := opts.Source.Lookup(d.Name); ok {
+			attrs = append(attrs, "set")
+		} else {
+			attrs = append(attrs, "not set")
+		}
+
+		usage := strings.ReplaceAll(d.Description, "\n", "\n    \t")
+		if usage != "" {
+			usage += " "
+		}
+		fmt.Fprintf(bw, "    \t%s(%s)\n", usage, strings.Join(attrs, ", "))
+	}
+	return bw.Flush()
+}